@@ -0,0 +1,224 @@
+package build
+
+import (
+	"bytes"
+	"html"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var KindAdmonition = ast.NewNodeKind("Admonition")
+
+// Admonition is a callout block, written either as a ":::kind Title" container
+// or as a GitHub-style "> [!KIND]" blockquote.
+type Admonition struct {
+	ast.BaseBlock
+	AdmonitionKind string
+	Title          string
+	fenceLength    int
+}
+
+func (n *Admonition) Kind() ast.NodeKind {
+	return KindAdmonition
+}
+
+func (n *Admonition) containerFenceLength() int {
+	return n.fenceLength
+}
+
+func (n *Admonition) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Kind": n.AdmonitionKind, "Title": n.Title}, nil)
+}
+
+// the default title of each admonition kind
+var admonitionTitles = map[string]string{
+	"note":      "Note",
+	"tip":       "Tip",
+	"info":      "Info",
+	"important": "Important",
+	"warning":   "Warning",
+	"danger":    "Danger",
+	"caution":   "Caution",
+}
+
+// kinds supported by GitHub-style blockquote alerts
+var githubAlertKinds = map[string]bool{
+	"note":      true,
+	"tip":       true,
+	"important": true,
+	"warning":   true,
+	"caution":   true,
+}
+
+// containerExtension adds admonitions and code groups.
+type containerExtension struct{}
+
+func (e containerExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(util.Prioritized(&containerBlockParser{}, 750)),
+		parser.WithASTTransformers(util.Prioritized(&admonitionAstTransformer{}, 400)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&admonitionRenderer{}, 100), util.Prioritized(&codeGroupRenderer{}, 100)))
+}
+
+// containerBlockParser parses ":::name" containers, closed by a line with at least as many colons.
+// Containers can be nested by using more colons on the outer one.
+// The name is either an admonition kind followed by an optional title, or "code-group".
+type containerBlockParser struct{}
+
+// containerNode is a block opened by containerBlockParser.
+type containerNode interface {
+	ast.Node
+	containerFenceLength() int
+}
+
+func (b containerBlockParser) Trigger() []byte {
+	return []byte{':'}
+}
+
+func (b containerBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+	fenceLength := countContainerFence(line[pos:])
+	if fenceLength < 3 {
+		return nil, parser.NoChildren
+	}
+	info := strings.TrimSpace(string(line[pos+fenceLength:]))
+	name, title, _ := strings.Cut(info, " ")
+	name = strings.ToLower(name)
+	var node ast.Node
+	if name == "code-group" {
+		node = &CodeGroup{fenceLength: fenceLength}
+	} else if defaultTitle, ok := admonitionTitles[name]; ok {
+		title = strings.TrimSpace(title)
+		if title == "" {
+			title = defaultTitle
+		}
+		node = &Admonition{AdmonitionKind: name, Title: title, fenceLength: fenceLength}
+	} else {
+		return nil, parser.NoChildren
+	}
+	reader.Advance(segment.Len() - util.TrimRightSpaceLength(line))
+	return node, parser.HasChildren
+}
+
+func (b containerBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+	container := node.(containerNode)
+	w, pos := util.IndentWidth(line, reader.LineOffset())
+	if w < 4 {
+		fenceLength := countContainerFence(line[pos:])
+		if fenceLength >= container.containerFenceLength() && util.IsBlank(line[pos+fenceLength:]) {
+			reader.Advance(segment.Len() - util.TrimRightSpaceLength(line))
+			return parser.Close
+		}
+	}
+	return parser.Continue | parser.HasChildren
+}
+
+func (b containerBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (b containerBlockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (b containerBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+func countContainerFence(line []byte) int {
+	i := 0
+	for i < len(line) && line[i] == ':' {
+		i++
+	}
+	return i
+}
+
+// admonitionAstTransformer replaces blockquotes starting with a "[!KIND]" line with admonitions.
+type admonitionAstTransformer struct{}
+
+func (t admonitionAstTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	var blockquotes []*ast.Blockquote
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if blockquote, ok := n.(*ast.Blockquote); ok && entering {
+			blockquotes = append(blockquotes, blockquote)
+		}
+		return ast.WalkContinue, nil
+	})
+	source := reader.Source()
+	for _, blockquote := range blockquotes {
+		paragraph, ok := blockquote.FirstChild().(*ast.Paragraph)
+		if !ok || paragraph.Lines().Len() == 0 {
+			continue
+		}
+		firstLine := paragraph.Lines().At(0)
+		marker := string(bytes.TrimSpace(firstLine.Value(source)))
+		if !strings.HasPrefix(marker, "[!") || !strings.HasSuffix(marker, "]") {
+			continue
+		}
+		kind := strings.ToLower(marker[2 : len(marker)-1])
+		if !githubAlertKinds[kind] {
+			continue
+		}
+		for child := paragraph.FirstChild(); child != nil; {
+			next := child.NextSibling()
+			textNode, ok := child.(*ast.Text)
+			if !ok || textNode.Segment.Start >= firstLine.Stop {
+				break
+			}
+			paragraph.RemoveChild(paragraph, child)
+			child = next
+		}
+		if !paragraph.HasChildren() {
+			blockquote.RemoveChild(blockquote, paragraph)
+		}
+		admonition := &Admonition{AdmonitionKind: kind, Title: admonitionTitles[kind]}
+		for child := blockquote.FirstChild(); child != nil; {
+			next := child.NextSibling()
+			admonition.AppendChild(admonition, child)
+			child = next
+		}
+		blockquote.Parent().ReplaceChild(blockquote.Parent(), blockquote, admonition)
+	}
+}
+
+type admonitionRenderer struct{}
+
+func (r admonitionRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindAdmonition, r.renderAdmonition)
+}
+
+func (r admonitionRenderer) renderAdmonition(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	admonition := node.(*Admonition)
+	if !entering {
+		w.WriteString("</aside>\n")
+		return ast.WalkContinue, nil
+	}
+	w.WriteString("<aside class=\"admonition admonition-" + admonition.AdmonitionKind + "\" role=\"note\">\n")
+	w.WriteString("<p class=\"admonition-title\">")
+	w.WriteString(admonitionIcons[admonition.AdmonitionKind])
+	w.WriteString(html.EscapeString(admonition.Title))
+	w.WriteString("</p>\n")
+	return ast.WalkContinue, nil
+}
+
+const admonitionIconStart = `<svg class="admonition-icon" aria-hidden="true" xmlns="http://www.w3.org/2000/svg" width="16" height="16" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round">`
+
+var admonitionIcons = map[string]string{
+	"note":      admonitionIconStart + `<circle cx="12" cy="12" r="10"/><path d="M12 16v-4"/><path d="M12 8h.01"/></svg>`,
+	"info":      admonitionIconStart + `<circle cx="12" cy="12" r="10"/><path d="M12 16v-4"/><path d="M12 8h.01"/></svg>`,
+	"tip":       admonitionIconStart + `<path d="M9 18h6"/><path d="M10 22h4"/><path d="M12 2a7 7 0 0 0-4 12.7c.6.5 1 1.3 1 2.3h6c0-1 .4-1.8 1-2.3A7 7 0 0 0 12 2z"/></svg>`,
+	"important": admonitionIconStart + `<path d="M21 15a2 2 0 0 1-2 2H7l-4 4V5a2 2 0 0 1 2-2h14a2 2 0 0 1 2 2z"/><path d="M12 7v4"/><path d="M12 14h.01"/></svg>`,
+	"warning":   admonitionIconStart + `<path d="M10.3 3.9 1.8 18a2 2 0 0 0 1.7 3h17a2 2 0 0 0 1.7-3L13.7 3.9a2 2 0 0 0-3.4 0z"/><path d="M12 9v4"/><path d="M12 17h.01"/></svg>`,
+	"danger":    admonitionIconStart + `<path d="M7.9 2h8.2L22 7.9v8.2L16.1 22H7.9L2 16.1V7.9z"/><path d="M12 8v4"/><path d="M12 16h.01"/></svg>`,
+	"caution":   admonitionIconStart + `<path d="M7.9 2h8.2L22 7.9v8.2L16.1 22H7.9L2 16.1V7.9z"/><path d="M12 8v4"/><path d="M12 16h.01"/></svg>`,
+}
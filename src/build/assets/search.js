@@ -0,0 +1,244 @@
+(() => {
+  const searchElements = document.querySelectorAll(".search");
+  if (searchElements.length < 1) {
+    return;
+  }
+
+  let indexPromise = null;
+
+  function loadIndex(src) {
+    if (indexPromise === null) {
+      indexPromise = fetch(src)
+        .then((response) => response.json())
+        .then(createIndex);
+    }
+    return indexPromise;
+  }
+
+  function tokenize(text) {
+    return text
+      .toLowerCase()
+      .split(/[^\p{L}\p{N}_]+/u)
+      .filter((word) => word !== "");
+  }
+
+  function createIndex(pages) {
+    const entries = [];
+    // word => entry index => weight
+    const words = new Map();
+    for (const page of pages) {
+      for (const section of page.sections) {
+        const entryIndex = entries.length;
+        entries.push({
+          title: page.title,
+          heading: section.heading,
+          text: section.text,
+          href: section.id === "" ? page.href : page.href + "#" + section.id,
+        });
+        addWords(words, entryIndex, page.title, 3);
+        addWords(words, entryIndex, section.heading, 2);
+        addWords(words, entryIndex, section.text, 1);
+      }
+    }
+    return { entries, words };
+  }
+
+  function addWords(words, entryIndex, text, weight) {
+    for (const word of tokenize(text)) {
+      let weights = words.get(word);
+      if (weights === undefined) {
+        weights = new Map();
+        words.set(word, weights);
+      }
+      weights.set(entryIndex, Math.max(weights.get(entryIndex) ?? 0, weight));
+    }
+  }
+
+  function allowedEditDistance(term) {
+    if (term.length < 4) {
+      return 0;
+    }
+    if (term.length < 7) {
+      return 1;
+    }
+    return 2;
+  }
+
+  // returns max + 1 if the distance is greater than max
+  function editDistance(a, b, max) {
+    if (Math.abs(a.length - b.length) > max) {
+      return max + 1;
+    }
+    let previous = Array.from({ length: b.length + 1 }, (_, i) => i);
+    for (let i = 1; i <= a.length; i++) {
+      const current = [i];
+      let rowMin = i;
+      for (let j = 1; j <= b.length; j++) {
+        const cost = a[i - 1] === b[j - 1] ? 0 : 1;
+        current[j] = Math.min(
+          previous[j] + 1,
+          current[j - 1] + 1,
+          previous[j - 1] + cost
+        );
+        rowMin = Math.min(rowMin, current[j]);
+      }
+      if (rowMin > max) {
+        return max + 1;
+      }
+      previous = current;
+    }
+    return previous[b.length];
+  }
+
+  function search(index, query) {
+    const terms = tokenize(query);
+    if (terms.length < 1) {
+      return [];
+    }
+    let scores = null;
+    for (const term of terms) {
+      const maxDistance = allowedEditDistance(term);
+      const termScores = new Map();
+      for (const [word, weights] of index.words) {
+        let multiplier;
+        if (word === term) {
+          multiplier = 3;
+        } else if (word.startsWith(term)) {
+          multiplier = 2;
+        } else if (
+          maxDistance > 0 &&
+          editDistance(word, term, maxDistance) <= maxDistance
+        ) {
+          multiplier = 1;
+        } else {
+          continue;
+        }
+        for (const [entryIndex, weight] of weights) {
+          const score = weight * multiplier;
+          termScores.set(
+            entryIndex,
+            Math.max(termScores.get(entryIndex) ?? 0, score)
+          );
+        }
+      }
+      if (scores === null) {
+        scores = termScores;
+        continue;
+      }
+      const mergedScores = new Map();
+      for (const [entryIndex, score] of scores) {
+        const termScore = termScores.get(entryIndex);
+        if (termScore !== undefined) {
+          mergedScores.set(entryIndex, score + termScore);
+        }
+      }
+      scores = mergedScores;
+    }
+    return Array.from(scores)
+      .sort((a, b) => b[1] - a[1])
+      .slice(0, 10)
+      .map(([entryIndex]) => index.entries[entryIndex]);
+  }
+
+  function createSnippet(text, query) {
+    const lowerCaseText = text.toLowerCase();
+    let start = 0;
+    for (const term of tokenize(query)) {
+      const termIndex = lowerCaseText.indexOf(term);
+      if (termIndex > -1) {
+        start = Math.max(0, termIndex - 30);
+        break;
+      }
+    }
+    let snippet = text.slice(start, start + 120);
+    if (start > 0) {
+      snippet = "…" + snippet;
+    }
+    if (start + 120 < text.length) {
+      snippet = snippet + "…";
+    }
+    return snippet;
+  }
+
+  function renderResults(resultsElement, results, query) {
+    resultsElement.replaceChildren();
+    if (query.trim() === "") {
+      resultsElement.classList.add("hidden");
+      return;
+    }
+    resultsElement.classList.remove("hidden");
+    if (results.length < 1) {
+      const item = document.createElement("li");
+      item.className = "search-no-results";
+      item.textContent = "No results";
+      resultsElement.append(item);
+      return;
+    }
+    for (const result of results) {
+      const item = document.createElement("li");
+      const link = document.createElement("a");
+      link.className = "search-result";
+      link.href = result.href;
+      const title = document.createElement("span");
+      title.className = "search-result-title";
+      if (result.heading === "" || result.heading === result.title) {
+        title.textContent = result.title;
+      } else {
+        title.textContent = result.title + " › " + result.heading;
+      }
+      link.append(title);
+      if (result.text !== "") {
+        const snippet = document.createElement("span");
+        snippet.className = "search-result-snippet";
+        snippet.textContent = createSnippet(result.text, query);
+        link.append(snippet);
+      }
+      item.append(link);
+      resultsElement.append(item);
+    }
+  }
+
+  for (const searchElement of searchElements) {
+    const input = searchElement.querySelector(".search-input");
+    const resultsElement = searchElement.querySelector(".search-results");
+    const indexSrc = searchElement.dataset.searchIndex;
+
+    input.addEventListener("focus", () => {
+      loadIndex(indexSrc);
+    });
+    input.addEventListener("input", async () => {
+      const query = input.value;
+      const index = await loadIndex(indexSrc);
+      if (input.value !== query) {
+        return;
+      }
+      renderResults(resultsElement, search(index, query), query);
+    });
+    searchElement.addEventListener("keydown", (e) => {
+      const links = Array.from(resultsElement.querySelectorAll("a"));
+      const focusedIndex = links.indexOf(document.activeElement);
+      if (e.key === "ArrowDown") {
+        e.preventDefault();
+        links[Math.min(focusedIndex + 1, links.length - 1)]?.focus();
+      } else if (e.key === "ArrowUp") {
+        e.preventDefault();
+        if (focusedIndex > 0) {
+          links[focusedIndex - 1].focus();
+        } else {
+          input.focus();
+        }
+      } else if (e.key === "Enter" && document.activeElement === input) {
+        links[0]?.click();
+      } else if (e.key === "Escape") {
+        input.value = "";
+        renderResults(resultsElement, [], "");
+        input.blur();
+      }
+    });
+    document.addEventListener("click", (e) => {
+      if (!searchElement.contains(e.target)) {
+        resultsElement.classList.add("hidden");
+      }
+    });
+  }
+})();
@@ -0,0 +1,235 @@
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/adrg/frontmatter"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// pageLink is a link or image of a page.
+// destination is resolved by relativeLinksAstTransformer, and written is the destination inside the markdown.
+type pageLink struct {
+	destination string
+	written     string
+	line        int
+}
+
+// CheckLinks resolves every internal link of the pages against the pages and the sidebar,
+// and checks that fragments match a heading ID of the target page.
+// Pages are parsed with the same markdown syntaxes as the generated pages.
+func CheckLinks(markdownFilePaths []string, navSections []NavSection, markdownConfig MarkdownConfig) ([]*PageError, error) {
+	md := newMarkdown(markdownConfig)
+	headingIds := make(map[string]map[string]bool)
+	pageLinks := make(map[string][]pageLink)
+	for _, markdownFilePath := range markdownFilePaths {
+		file, err := os.ReadFile(markdownFilePath)
+		if err != nil {
+			return nil, err
+		}
+		var matter struct{}
+		pageMarkdown, err := frontmatter.Parse(bytes.NewReader(file), &matter)
+		if err != nil {
+			return nil, err
+		}
+		lineOffset := bytes.Count(file[:len(file)-len(pageMarkdown)], []byte("\n"))
+		pc := parser.NewContext()
+		pc.Set(markdownFilePathContextKey, markdownFilePath)
+		document := md.Parser().Parse(text.NewReader(pageMarkdown), parser.WithContext(pc))
+		headingIds[GetPageURLPath(markdownFilePath)] = collectHeadingIds(document)
+		pageLinks[markdownFilePath] = collectPageLinks(document, pageMarkdown, lineOffset, pc)
+	}
+
+	navPageHrefs := make(map[string]bool)
+	for _, section := range navSections {
+		navPageHrefs[section.Href] = true
+		for _, page := range flattenNavPages(section.Pages) {
+			navPageHrefs[page.Href] = true
+		}
+	}
+
+	var linkErrors []*PageError
+	for _, markdownFilePath := range markdownFilePaths {
+		urlPath := GetPageURLPath(markdownFilePath)
+		for _, link := range pageLinks[markdownFilePath] {
+			message := checkLink(urlPath, link, headingIds, navPageHrefs)
+			if message == "" {
+				continue
+			}
+			linkErrors = append(linkErrors, &PageError{markdownFilePath, link.line, message})
+		}
+	}
+	return linkErrors, nil
+}
+
+func checkLink(urlPath string, link pageLink, headingIds map[string]map[string]bool, navPageHrefs map[string]bool) string {
+	destinationURL, err := url.Parse(link.destination)
+	if err != nil {
+		return fmt.Sprintf("invalid link: %s", link.written)
+	}
+	if destinationURL.Scheme != "" || destinationURL.Host != "" {
+		return ""
+	}
+	baseURL := &url.URL{Scheme: "http", Host: "localhost", Path: urlPath}
+	targetPath := baseURL.ResolveReference(destinationURL).Path
+	if targetPath != "/" {
+		targetPath = strings.TrimSuffix(targetPath, "/")
+	}
+	if path.Ext(targetPath) != "" {
+		if !staticFileExists(targetPath) {
+			return fmt.Sprintf("broken link: %s", link.written)
+		}
+		return ""
+	}
+	targetHeadingIds, ok := headingIds[targetPath]
+	if !ok {
+		if navPageHrefs[targetPath] {
+			return ""
+		}
+		return fmt.Sprintf("broken link: %s", link.written)
+	}
+	if destinationURL.Fragment != "" && !targetHeadingIds[destinationURL.Fragment] {
+		return fmt.Sprintf("missing anchor: %s", link.written)
+	}
+	return ""
+}
+
+func staticFileExists(urlPath string) bool {
+	for _, dir := range []string{"pages", "public"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(urlPath))); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func collectHeadingIds(document ast.Node) map[string]bool {
+	ids := make(map[string]bool)
+	ast.Walk(document, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Kind() != ast.KindHeading {
+			return ast.WalkContinue, nil
+		}
+		if id, ok := n.AttributeString("id"); ok {
+			ids[string(id.([]byte))] = true
+		}
+		return ast.WalkSkipChildren, nil
+	})
+	return ids
+}
+
+// collectPageLinks returns the destination of every link and image,
+// including links defined inside code blocks and in raw HTML.
+func collectPageLinks(document ast.Node, source []byte, lineOffset int, pc parser.Context) []pageLink {
+	writtenDestinations, _ := pc.Get(writtenDestinationsContextKey).(map[ast.Node]string)
+	var links []pageLink
+	addLink := func(n ast.Node, destination []byte) {
+		written, ok := writtenDestinations[n]
+		if !ok {
+			written = string(destination)
+		}
+		links = append(links, pageLink{string(destination), written, lineOffset + getNodeLine(n, source)})
+	}
+	ast.Walk(document, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Link:
+			addLink(n, n.Destination)
+		case *ast.Image:
+			addLink(n, n.Destination)
+		case *ast.RawHTML:
+			if n.Segments.Len() > 0 {
+				start, stop := n.Segments.At(0).Start, n.Segments.At(n.Segments.Len()-1).Stop
+				links = append(links, collectHTMLLinks(source, start, stop, lineOffset)...)
+			}
+		case *ast.HTMLBlock:
+			if n.Lines().Len() > 0 {
+				start, stop := n.Lines().At(0).Start, n.Lines().At(n.Lines().Len()-1).Stop
+				if n.HasClosure() {
+					stop = n.ClosureLine.Stop
+				}
+				links = append(links, collectHTMLLinks(source, start, stop, lineOffset)...)
+			}
+		case *ast.FencedCodeBlock:
+			// link definitions are the first lines of the code block
+			var definitions []ast.Attribute
+			for _, attribute := range n.Attributes() {
+				if strings.HasPrefix(string(attribute.Name), "link:") {
+					definitions = append(definitions, attribute)
+				}
+			}
+			fenceLine := getNodeLine(n, source)
+			if n.Info == nil && n.Lines().Len() > 0 {
+				fenceLine -= len(definitions) + 1
+			}
+			if !rendersCodeBlockLinks(n, source) {
+				return ast.WalkSkipChildren, nil
+			}
+			content := codeBlockContent(n, source)
+			for i, definition := range definitions {
+				// definitions that are never used don't create a link
+				target := strings.TrimPrefix(string(definition.Name), "link:")
+				if !strings.Contains(content, "$$"+target) {
+					continue
+				}
+				destination, _ := definition.Value.(string)
+				links = append(links, pageLink{destination, destination, lineOffset + fenceLine + 1 + i})
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return links
+}
+
+var htmlLinkAttributeRegex = regexp.MustCompile(`(?i)\s(?:href|src)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// collectHTMLLinks returns the href and src attributes of the raw HTML between start and stop in source.
+func collectHTMLLinks(source []byte, start int, stop int, lineOffset int) []pageLink {
+	var links []pageLink
+	for _, match := range htmlLinkAttributeRegex.FindAllSubmatchIndex(source[start:stop], -1) {
+		var destination string
+		if match[2] >= 0 {
+			destination = string(source[start+match[2] : start+match[3]])
+		} else {
+			destination = string(source[start+match[4] : start+match[5]])
+		}
+		destination = html.UnescapeString(destination)
+		line := bytes.Count(source[:start+match[0]], []byte("\n")) + 1
+		links = append(links, pageLink{destination, destination, lineOffset + line})
+	}
+	return links
+}
+
+// getNodeLine returns the 1-indexed line of the node inside source.
+func getNodeLine(node ast.Node, source []byte) int {
+	offset := -1
+	if codeBlock, ok := node.(*ast.FencedCodeBlock); ok && codeBlock.Info != nil {
+		offset = codeBlock.Info.Segment.Start
+	}
+	for n := node; offset < 0 && n != nil; n = n.FirstChild() {
+		if textNode, ok := n.(*ast.Text); ok {
+			offset = textNode.Segment.Start
+		}
+	}
+	for n := node; offset < 0 && n != nil; n = n.Parent() {
+		if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+			offset = n.Lines().At(0).Start
+		}
+	}
+	if offset < 0 {
+		return 1
+	}
+	return bytes.Count(source[:offset], []byte("\n")) + 1
+}
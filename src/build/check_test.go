@@ -0,0 +1,31 @@
+package build
+
+import (
+	"testing"
+)
+
+func TestCheckLinks(t *testing.T) {
+	setupTestProject(t, map[string]string{
+		"pages/index.md":  "---\ntitle: \"Home\"\n---\n\n[Guide](/guide#install)\n\n[Missing](./missing.md)\n\n[Anchor](/guide#missing)\n\n![Image](./image.png)\n\n[Public](/file.pdf)\n\n[Sidebar](/external)\n",
+		"pages/guide.md":  "---\ntitle: \"Guide\"\n---\n\n## Install\n\n[Home](./index.md)\n",
+		"public/file.pdf": "",
+	})
+	navSections := []NavSection{{Title: "External", Href: "/external"}}
+	linkErrors, err := CheckLinks([]string{"pages/index.md", "pages/guide.md"}, navSections, DefaultMarkdownConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"pages/index.md:7: broken link: ./missing.md",
+		"pages/index.md:9: missing anchor: /guide#missing",
+		"pages/index.md:11: broken link: ./image.png",
+	}
+	if len(linkErrors) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), linkErrors)
+	}
+	for i, linkError := range linkErrors {
+		if linkError.Error() != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], linkError.Error())
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"html"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/alecthomas/chroma"
@@ -41,6 +43,10 @@ func (a codeBlockLinksAstTransformer) Transform(node *ast.Document, reader text.
 			}
 		}
 		n.Lines().SetSliced(defCount, n.Lines().Len())
+		if markdownFilePath, ok := pc.Get(markdownFilePathContextKey).(string); ok {
+			resolveCodeBlockSnippets(n.(*ast.FencedCodeBlock), reader.Source(), markdownFilePath, pc)
+		}
+		renderCodeBlockDiagram(n.(*ast.FencedCodeBlock), reader.Source(), pc)
 		return ast.WalkContinue, nil
 	}
 	ast.Walk(node, walker)
@@ -58,10 +64,16 @@ func (r codeBlockLinksRenderer) renderCustomCodeBlockLinks(w util.BufWriter, sou
 	}
 	codeBlock := node.(*ast.FencedCodeBlock)
 
-	var content string
-	for i := 0; i < codeBlock.Lines().Len(); i++ {
-		line := codeBlock.Lines().At(i)
-		content += string(line.Value(source))
+	// diagrams are placed inside a container like code blocks so that they can be a tab of a code group
+	if svg, ok := node.AttributeString(codeBlockDiagramAttribute); ok {
+		w.WriteString(fmt.Sprintf("<div class=\"codeblock-container\"><div class=\"diagram\">%s</div></div>", svg.(string)))
+		return ast.WalkContinue, nil
+	}
+	content := codeBlockContent(codeBlock, source)
+	// Mermaid diagrams without a renderer are rendered in the browser
+	if codeBlockLanguage(codeBlock, source) == "mermaid" {
+		w.WriteString(fmt.Sprintf("<div class=\"codeblock-container\"><pre class=\"mermaid\">%s</pre></div>", html.EscapeString(content)))
+		return ast.WalkContinue, nil
 	}
 	for _, attribute := range node.Attributes() {
 		attributeName := string(attribute.Name)
@@ -72,26 +84,34 @@ func (r codeBlockLinksRenderer) renderCustomCodeBlockLinks(w util.BufWriter, sou
 		content = strings.ReplaceAll(content, "$$"+target, "__MALTA_CODEBLOCK_LINK_"+target)
 		content = strings.ReplaceAll(content, "$\\$"+target, "$$"+target)
 	}
-	lexer := lexers.Get(string(codeBlock.Language(source)))
+	meta := parseCodeBlockMeta(codeBlock, source)
+	language := codeBlockLanguage(codeBlock, source)
+	diffLanguage := false
+	if strings.HasPrefix(language, "diff-") {
+		language = strings.TrimPrefix(language, "diff-")
+		diffLanguage = true
+	}
+	content, diffLineKinds := parseDiffLines(content, diffLanguage)
+	lexer := lexers.Get(language)
 	if lexer == nil {
-		w.WriteString("<pre class=\"codeblock\"><code>")
-		w.WriteString(html.EscapeString(content))
-		w.WriteString("</code></pre>")
-		return ast.WalkContinue, nil
+		lexer = lexers.Fallback
 	}
 	lexer = chroma.Coalesce(lexer)
 
-	formatter := htmlFormatter.New(htmlFormatter.WithClasses(true), htmlFormatter.PreventSurroundingPre(true))
+	formatter := htmlFormatter.New(htmlFormatter.WithClasses(true), htmlFormatter.PreventSurroundingPre(true), htmlFormatter.WithLineNumbers(meta.showLineNumbers), htmlFormatter.HighlightLines(meta.highlightLines))
 
 	iterator, err := lexer.Tokenise(nil, content)
 	if err != nil {
 		return ast.WalkStop, err
 	}
 	buf := new(bytes.Buffer)
-	// random style
-	formatter.Format(buf, styles.GitHub, iterator)
+	// colors are defined by the highlight stylesheet, so the style is ignored
+	formatter.Format(buf, styles.Fallback, iterator)
 
-	html := buf.String()
+	highlighted := buf.String()
+	if diffLineKinds != nil {
+		highlighted = addDiffLineClasses(highlighted, diffLineKinds)
+	}
 	for _, attribute := range node.Attributes() {
 		attributeName := string(attribute.Name)
 		if !strings.HasPrefix(attributeName, "link:") {
@@ -99,12 +119,80 @@ func (r codeBlockLinksRenderer) renderCustomCodeBlockLinks(w util.BufWriter, sou
 		}
 		target := strings.Replace(attributeName, "link:", "", 1)
 		dest := attribute.Value.(string)
-		html = strings.ReplaceAll(html, "__MALTA_CODEBLOCK_LINK_"+target, fmt.Sprintf("<a href=\"%s\">%s</a>", dest, target))
+		highlighted = strings.ReplaceAll(highlighted, "__MALTA_CODEBLOCK_LINK_"+target, fmt.Sprintf("<a href=\"%s\">%s</a>", dest, target))
 	}
 
-	w.WriteString(fmt.Sprintf("<pre class=\"codeblock\"><code class=\"%s\">", string(codeBlock.Language(source))))
-	w.WriteString(html)
-	w.WriteString("</code class=%s></pre>")
+	w.WriteString("<div class=\"codeblock-container\">")
+	if meta.title != "" {
+		w.WriteString(fmt.Sprintf("<div class=\"codeblock-title\">%s</div>", html.EscapeString(meta.title)))
+	}
+	w.WriteString(fmt.Sprintf("<pre class=\"codeblock chroma\"><code class=\"%s\">", html.EscapeString(language)))
+	w.WriteString(highlighted)
+	w.WriteString("</code></pre></div>")
 
 	return ast.WalkContinue, nil
 }
+
+// rendersCodeBlockLinks reports whether the link definitions of the code block are rendered as links.
+// Diagrams are rendered as images instead of code.
+func rendersCodeBlockLinks(codeBlock *ast.FencedCodeBlock, source []byte) bool {
+	if _, ok := codeBlock.AttributeString(codeBlockDiagramAttribute); ok {
+		return false
+	}
+	return codeBlockLanguage(codeBlock, source) != "mermaid"
+}
+
+// codeBlockMeta holds the options defined after the language in the fence info string,
+// for example: ```ts title="auth.ts" {4-7} showLineNumbers
+type codeBlockMeta struct {
+	title           string
+	highlightLines  [][2]int
+	showLineNumbers bool
+}
+
+var codeBlockMetaRegex = regexp.MustCompile(`title="([^"]*)"|title='([^']*)'|\{([^}]*)\}|\S+`)
+
+func parseCodeBlockMeta(codeBlock *ast.FencedCodeBlock, source []byte) codeBlockMeta {
+	var meta codeBlockMeta
+	if codeBlock.Info == nil {
+		return meta
+	}
+	info := strings.TrimSpace(string(codeBlock.Info.Segment.Value(source)))
+	_, metaString, found := strings.Cut(info, " ")
+	if !found {
+		return meta
+	}
+	for _, match := range codeBlockMetaRegex.FindAllStringSubmatch(metaString, -1) {
+		switch {
+		case strings.HasPrefix(match[0], "title="):
+			meta.title = match[1] + match[2]
+		case strings.HasPrefix(match[0], "{"):
+			meta.highlightLines = append(meta.highlightLines, parseLineRanges(match[3])...)
+		case match[0] == "showLineNumbers":
+			meta.showLineNumbers = true
+		}
+	}
+	return meta
+}
+
+// parseLineRanges parses comma separated line numbers and ranges like "1,4-7".
+// Invalid items are ignored.
+func parseLineRanges(value string) [][2]int {
+	var ranges [][2]int
+	for _, item := range strings.Split(value, ",") {
+		start, end, isRange := strings.Cut(strings.TrimSpace(item), "-")
+		if !isRange {
+			end = start
+		}
+		startLine, err := strconv.Atoi(strings.TrimSpace(start))
+		if err != nil {
+			continue
+		}
+		endLine, err := strconv.Atoi(strings.TrimSpace(end))
+		if err != nil || endLine < startLine {
+			continue
+		}
+		ranges = append(ranges, [2]int{startLine, endLine})
+	}
+	return ranges
+}
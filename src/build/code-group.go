@@ -0,0 +1,67 @@
+package build
+
+import (
+	"html"
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+var KindCodeGroup = ast.NewNodeKind("CodeGroup")
+
+// CodeGroup is a ":::code-group" container. Its code blocks are shown as tabs,
+// labelled by the title of each code block.
+type CodeGroup struct {
+	ast.BaseBlock
+	fenceLength int
+}
+
+func (n *CodeGroup) Kind() ast.NodeKind {
+	return KindCodeGroup
+}
+
+func (n *CodeGroup) containerFenceLength() int {
+	return n.fenceLength
+}
+
+func (n *CodeGroup) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+type codeGroupRenderer struct{}
+
+func (r codeGroupRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindCodeGroup, r.renderCodeGroup)
+}
+
+// renderCodeGroup renders the tabs hidden, so that the code blocks are stacked when JavaScript is disabled.
+func (r codeGroupRenderer) renderCodeGroup(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		w.WriteString("</div>\n")
+		return ast.WalkContinue, nil
+	}
+	w.WriteString("<div class=\"code-group\">\n")
+	w.WriteString("<div class=\"code-group-tabs\" role=\"tablist\" hidden>")
+	tabCount := 0
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		codeBlock, ok := child.(*ast.FencedCodeBlock)
+		if !ok {
+			continue
+		}
+		tabCount++
+		label := parseCodeBlockMeta(codeBlock, source).title
+		if label == "" {
+			label = codeBlockLanguage(codeBlock, source)
+		}
+		if label == "" {
+			label = "Code " + strconv.Itoa(tabCount)
+		}
+		w.WriteString("<button type=\"button\" role=\"tab\" class=\"code-group-tab\">")
+		w.WriteString(html.EscapeString(label))
+		w.WriteString("</button>")
+	}
+	w.WriteString("</div>\n")
+	return ast.WalkContinue, nil
+}
@@ -0,0 +1,56 @@
+package build
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseConfigFileBuiltInAssetConflict(t *testing.T) {
+	tests := []struct {
+		config string
+		field  string
+	}{
+		{`{"name": "Test", "domain": "https://example.com", "description": "Test", "css": ["./main.css"]}`, "css"},
+		{`{"name": "Test", "domain": "https://example.com", "description": "Test", "css": ["highlight.css"]}`, "css"},
+		{`{"name": "Test", "domain": "https://example.com", "description": "Test", "scripts": ["search.js"]}`, "scripts"},
+	}
+	for _, test := range tests {
+		setupTestProject(t, map[string]string{"malta.config.json": test.config})
+		_, err := ParseConfigFile()
+		var invalidConfigError *InvalidConfigError
+		if !errors.As(err, &invalidConfigError) || invalidConfigError.Field != test.field {
+			t.Errorf("%s: expected invalid %s, got %v", test.config, test.field, err)
+		}
+	}
+
+	setupTestProject(t, map[string]string{
+		"malta.config.json": `{"name": "Test", "domain": "https://example.com", "description": "Test", "css": ["styles/main.css"], "scripts": ["scripts/search.js"]}`,
+	})
+	if _, err := ParseConfigFile(); err != nil {
+		t.Errorf("expected nested assets with built-in names to be accepted, got %v", err)
+	}
+}
+
+func TestParseConfigFileMermaid(t *testing.T) {
+	setupTestProject(t, map[string]string{
+		"malta.config.json": `{"name": "Test", "domain": "https://example.com", "description": "Test"}`,
+	})
+	config, err := ParseConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Mermaid != DefaultMermaidConfig() {
+		t.Errorf("expected the default Mermaid script, got %v", config.Mermaid)
+	}
+
+	setupTestProject(t, map[string]string{
+		"malta.config.json": `{"name": "Test", "domain": "https://example.com", "description": "Test", "mermaid": {"src": ""}}`,
+	})
+	config, err = ParseConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Mermaid.Src != "" {
+		t.Errorf("expected an empty src to disable the Mermaid script, got %s", config.Mermaid.Src)
+	}
+}
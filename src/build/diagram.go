@@ -0,0 +1,103 @@
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+)
+
+var diagramRenderersContextKey = parser.NewContextKey()
+
+// set when the page has mermaid code blocks that are rendered in the browser
+var mermaidDiagramsContextKey = parser.NewContextKey()
+
+// code block attribute set by codeBlockLinksAstTransformer when a diagram is rendered at build time
+const codeBlockDiagramAttribute = "diagram"
+
+// DiagramRenderer renders the source of a diagram code block to an SVG image.
+type DiagramRenderer interface {
+	RenderSVG(source string) (string, error)
+}
+
+// MermaidConfig defines the Mermaid script loaded on pages with mermaid code blocks
+// that are not rendered at build time.
+// The script can be hosted with the site, and Integrity is the optional subresource integrity hash.
+// No script is loaded if Src is empty.
+type MermaidConfig struct {
+	Src       string `json:"src"`
+	Integrity string `json:"integrity"`
+}
+
+// DefaultMermaidConfig loads Mermaid from jsDelivr, without an integrity hash.
+func DefaultMermaidConfig() MermaidConfig {
+	return MermaidConfig{
+		Src: "https://cdn.jsdelivr.net/npm/mermaid@10.9.1/dist/mermaid.min.js",
+	}
+}
+
+// CommandDiagramRenderer renders diagrams with an external command,
+// which reads the diagram source from stdin and writes the SVG image to stdout,
+// for example "dot -Tsvg".
+type CommandDiagramRenderer struct {
+	Command []string
+}
+
+func (r *CommandDiagramRenderer) RenderSVG(source string) (string, error) {
+	cmd := exec.Command(r.Command[0], r.Command[1:]...)
+	cmd.Stdin = strings.NewReader(source)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return "", fmt.Errorf("%s: %s", r.Command[0], message)
+	}
+	// the XML declaration, doctype, and comments before the image aren't allowed inside HTML
+	svg := stdout.String()
+	start := strings.Index(svg, "<svg")
+	if start < 0 {
+		return "", fmt.Errorf("%s did not output an SVG image", r.Command[0])
+	}
+	return strings.TrimSpace(svg[start:]), nil
+}
+
+// renderCodeBlockDiagram renders the code block with the diagram renderer of its language, if any.
+// The image is stored as an attribute and replaces the code block when rendering the page.
+func renderCodeBlockDiagram(codeBlock *ast.FencedCodeBlock, source []byte, pc parser.Context) {
+	renderers, _ := pc.Get(diagramRenderersContextKey).(map[string]DiagramRenderer)
+	language := codeBlockLanguage(codeBlock, source)
+	diagramRenderer, ok := renderers[language]
+	if !ok {
+		if language == "mermaid" {
+			pc.Set(mermaidDiagramsContextKey, true)
+		}
+		return
+	}
+	diagram := codeBlockContent(codeBlock, source)
+	svg, err := diagramRenderer.RenderSVG(diagram)
+	if err != nil {
+		addPageIssue(pc, getNodeLine(codeBlock, source), fmt.Sprintf("failed to render diagram: %v", err))
+		return
+	}
+	codeBlock.SetAttributeString(codeBlockDiagramAttribute, svg)
+}
+
+// codeBlockContent returns the content of the code block, including the resolved snippets.
+func codeBlockContent(codeBlock *ast.FencedCodeBlock, source []byte) string {
+	if content, ok := codeBlock.AttributeString(codeBlockContentAttribute); ok {
+		return content.(string)
+	}
+	var content strings.Builder
+	for i := 0; i < codeBlock.Lines().Len(); i++ {
+		line := codeBlock.Lines().At(i)
+		content.Write(line.Value(source))
+	}
+	return content.String()
+}
@@ -0,0 +1,89 @@
+package build
+
+import (
+	"regexp"
+	"strings"
+)
+
+type diffLineKind int
+
+const (
+	diffLineUnchanged diffLineKind = iota
+	diffLineAdded
+	diffLineRemoved
+)
+
+// a "[!code ++]" or "[!code --]" comment at the end of a line
+var diffMarkerRegex = regexp.MustCompile(`\s*(?://|#|--|<!--|/\*)\s*\[!code (\+\+|--)\]\s*(?:-->|\*/)?\s*$`)
+
+// "[!!code ++]" is rendered as "[!code ++]" without marking the line
+var escapedDiffMarkerRegex = regexp.MustCompile(`\[!!code (\+\+|--)\]`)
+
+// parseDiffLines removes the diff markers from content and returns the kind of each line.
+// When diffLanguage is true, lines starting with "+" or "-" are added or removed lines,
+// and lines starting with a space are unchanged, like in a diff.
+// Otherwise, lines are marked with "[!code ++]" and "[!code --]" comments.
+// kinds is nil if content has no diff markers and isn't a diff.
+func parseDiffLines(content string, diffLanguage bool) (string, []diffLineKind) {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	kinds := make([]diffLineKind, len(lines))
+	changed := false
+	for i, line := range lines {
+		text, newline := strings.CutSuffix(line, "\n")
+		if diffLanguage {
+			if strings.HasPrefix(text, "+") {
+				kinds[i] = diffLineAdded
+			} else if strings.HasPrefix(text, "-") {
+				kinds[i] = diffLineRemoved
+			}
+			if text != "" && strings.ContainsAny(text[:1], "+- ") {
+				text = text[1:]
+			}
+		} else if match := diffMarkerRegex.FindStringSubmatchIndex(text); match != nil {
+			if text[match[2]:match[3]] == "++" {
+				kinds[i] = diffLineAdded
+			} else {
+				kinds[i] = diffLineRemoved
+			}
+			text = text[:match[0]]
+		} else {
+			text = escapedDiffMarkerRegex.ReplaceAllString(text, "[!code $1]")
+		}
+		if kinds[i] != diffLineUnchanged {
+			changed = true
+		}
+		if newline {
+			text += "\n"
+		}
+		lines[i] = text
+	}
+	if !changed && !diffLanguage {
+		return strings.Join(lines, ""), nil
+	}
+	return strings.Join(lines, ""), kinds
+}
+
+// the start of a line in the Chroma output
+var highlightedLineRegex = regexp.MustCompile(`<span class="line( hl)?"`)
+
+// addDiffLineClasses adds the diff classes to the lines of the Chroma output.
+func addDiffLineClasses(highlighted string, kinds []diffLineKind) string {
+	i := 0
+	return highlightedLineRegex.ReplaceAllStringFunc(highlighted, func(lineStart string) string {
+		if i >= len(kinds) {
+			return lineStart
+		}
+		kind := kinds[i]
+		i++
+		switch kind {
+		case diffLineAdded:
+			return strings.TrimSuffix(lineStart, "\"") + " diff-add\""
+		case diffLineRemoved:
+			return strings.TrimSuffix(lineStart, "\"") + " diff-remove\""
+		}
+		return lineStart
+	})
+}
@@ -0,0 +1,27 @@
+package build
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDiffLines(t *testing.T) {
+	tests := []struct {
+		content      string
+		diffLanguage bool
+		expected     string
+		kinds        []diffLineKind
+	}{
+		{"a := 1\nb := 2\n", false, "a := 1\nb := 2\n", nil},
+		{"a := 1 // [!code --]\na := 2 // [!code ++]\nb := 3\n", false, "a := 1\na := 2\nb := 3\n", []diffLineKind{diffLineRemoved, diffLineAdded, diffLineUnchanged}},
+		{"<p>a</p> <!-- [!code ++] -->\n", false, "<p>a</p>\n", []diffLineKind{diffLineAdded}},
+		{"// [!!code ++]\n", false, "// [!code ++]\n", nil},
+		{" a\n-b\n+c\n", true, "a\nb\nc\n", []diffLineKind{diffLineUnchanged, diffLineRemoved, diffLineAdded}},
+	}
+	for _, test := range tests {
+		content, kinds := parseDiffLines(test.content, test.diffLanguage)
+		if content != test.expected || !reflect.DeepEqual(kinds, test.kinds) {
+			t.Errorf("%q: expected %q %v, got %q %v", test.content, test.expected, test.kinds, content, kinds)
+		}
+	}
+}
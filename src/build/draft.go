@@ -0,0 +1,82 @@
+package build
+
+import (
+	"time"
+)
+
+// publishDateLayout is the format of the publish_date attribute.
+const publishDateLayout = "2006-01-02"
+
+// parsePublishDate accepts a date, or a date and time in RFC 3339 format.
+func parsePublishDate(value string) (time.Time, error) {
+	if publishDate, err := time.Parse(time.RFC3339, value); err == nil {
+		return publishDate, nil
+	}
+	return time.ParseInLocation(publishDateLayout, value, time.Local)
+}
+
+// scheduled reports whether the page has a publish date after now.
+func (matter pageMatter) scheduled(now time.Time) bool {
+	if matter.PublishDate == "" {
+		return false
+	}
+	publishDate, err := parsePublishDate(matter.PublishDate)
+	return err == nil && publishDate.After(now)
+}
+
+// unpublished reports whether the page is a draft or is scheduled to be published later.
+func (matter pageMatter) unpublished(now time.Time) bool {
+	return matter.Draft || matter.scheduled(now)
+}
+
+// ExcludeDraftPages returns the pages that are published at the time of the build,
+// and the URL paths of the draft and scheduled pages that were excluded.
+func ExcludeDraftPages(markdownFilePaths []string) ([]string, map[string]bool, error) {
+	now := time.Now()
+	publishedFilePaths := []string{}
+	draftURLPaths := make(map[string]bool)
+	for _, markdownFilePath := range markdownFilePaths {
+		matter, err := readPageMatter(markdownFilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		if matter.unpublished(now) {
+			draftURLPaths[GetPageURLPath(markdownFilePath)] = true
+			continue
+		}
+		publishedFilePaths = append(publishedFilePaths, markdownFilePath)
+	}
+	return publishedFilePaths, draftURLPaths, nil
+}
+
+// ExcludeDraftNavPages removes the links to draft pages from the sidebar.
+// Sections and groups with a draft landing page are kept as a heading if they still contain pages.
+func ExcludeDraftNavPages(navSections []NavSection, draftURLPaths map[string]bool) []NavSection {
+	sections := []NavSection{}
+	for _, section := range navSections {
+		if draftURLPaths[section.Href] {
+			section.Href = ""
+		}
+		section.Pages = excludeDraftNavPages(section.Pages, draftURLPaths)
+		if section.Href == "" && len(section.Pages) == 0 {
+			continue
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+func excludeDraftNavPages(navPages []NavPage, draftURLPaths map[string]bool) []NavPage {
+	pages := []NavPage{}
+	for _, page := range navPages {
+		if draftURLPaths[page.Href] {
+			page.Href = ""
+		}
+		page.Pages = excludeDraftNavPages(page.Pages, draftURLPaths)
+		if page.Href == "" && len(page.Pages) == 0 {
+			continue
+		}
+		pages = append(pages, page)
+	}
+	return pages
+}
@@ -0,0 +1,44 @@
+package build
+
+import (
+	"fmt"
+	"io"
+
+	htmlFormatter "github.com/alecthomas/chroma/formatters/html"
+	"github.com/alecthomas/chroma/styles"
+)
+
+// HighlightConfig defines the Chroma styles used for syntax highlighting.
+type HighlightConfig struct {
+	LightStyle string `json:"light"`
+	DarkStyle  string `json:"dark"`
+}
+
+func DefaultHighlightConfig() HighlightConfig {
+	return HighlightConfig{
+		LightStyle: "github",
+		DarkStyle:  "monokai",
+	}
+}
+
+func isHighlightStyle(name string) bool {
+	_, ok := styles.Registry[name]
+	return ok
+}
+
+// GenerateHighlightCSS writes the stylesheet for highlighted code blocks.
+// The dark style is used when the user prefers a dark color scheme.
+func GenerateHighlightCSS(config HighlightConfig, dst io.Writer) error {
+	formatter := htmlFormatter.New(htmlFormatter.WithClasses(true))
+	if err := formatter.WriteCSS(dst, styles.Get(config.LightStyle)); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprint(dst, "\n@media (prefers-color-scheme: dark) {\n"); err != nil {
+		return err
+	}
+	if err := formatter.WriteCSS(dst, styles.Get(config.DarkStyle)); err != nil {
+		return err
+	}
+	_, err := fmt.Fprint(dst, "}\n")
+	return err
+}
@@ -9,18 +9,16 @@ import (
 	"html/template"
 	"io"
 	"io/fs"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/adrg/frontmatter"
 	"github.com/pilcrowOnPaper/malta/utils"
 	"github.com/yuin/goldmark"
-	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
-	"github.com/yuin/goldmark/renderer"
-	"github.com/yuin/goldmark/util"
+	"github.com/yuin/goldmark/text"
 )
 
 //go:embed assets/*
@@ -79,18 +77,12 @@ func GetFaviconFile() ([]byte, error) {
 }
 
 var markdown goldmark.Markdown
-var tmpl *template.Template
+var defaultTemplate *template.Template
 
 func init() {
-	markdown = goldmark.New(goldmark.WithExtensions(extension.Table))
-	markdown.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(&codeBlockLinksAstTransformer{}, 500)), parser.WithAutoHeadingID())
-	markdown.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&codeBlockLinksRenderer{}, 100)))
+	markdown = newMarkdown(DefaultMarkdownConfig())
 
-	htmlTemplate, err := embedded.ReadFile("assets/template.html")
-	if err != nil {
-		log.Fatal("template.html does not exist")
-	}
-	tmpl, _ = template.New("html").Parse(string(htmlTemplate))
+	defaultTemplate = template.Must(parseDefaultTemplate())
 }
 
 type HTMLBuilder struct {
@@ -98,11 +90,23 @@ type HTMLBuilder struct {
 	siteDescription   string
 	siteDomain        string
 	siteTwitterHandle string
+	siteKeywords      []string
 	faviconHref       string
 	logoImageSrc      string
 	ogImageURL        string
 	navSections       []NavSection
 	styleSheetSrc     []string
+	liveReloadSrc     string
+	searchIndexSrc    string
+	searchScriptSrc   string
+	scriptSrc         []string
+	tmpl              *template.Template
+	markdown          goldmark.Markdown
+	diagramRenderers  map[string]DiagramRenderer
+	mermaidScript     MermaidConfig
+	copyButton        bool
+	assetHashing      bool
+	pageFiles         map[string]string
 }
 
 func NewBuilder(siteName string, siteDescription string, siteDomain string, navSections []NavSection, styleSheetNames []string) *HTMLBuilder {
@@ -111,6 +115,10 @@ func NewBuilder(siteName string, siteDescription string, siteDomain string, navS
 		siteDescription: siteDescription,
 		siteDomain:      siteDomain,
 		navSections:     navSections,
+		tmpl:            defaultTemplate,
+		markdown:        markdown,
+		mermaidScript:   DefaultMermaidConfig(),
+		pageFiles:       make(map[string]string),
 	}
 	for _, name := range styleSheetNames {
 		builder.styleSheetSrc = append(builder.styleSheetSrc, "/"+name)
@@ -122,6 +130,11 @@ func (builder *HTMLBuilder) SetSiteTwitterHandle(handle string) {
 	builder.siteTwitterHandle = handle
 }
 
+// SetSiteKeywords sets the keywords of pages that don't define their own.
+func (builder *HTMLBuilder) SetSiteKeywords(keywords []string) {
+	builder.siteKeywords = keywords
+}
+
 func (builder *HTMLBuilder) IncludeFavicon() {
 	builder.faviconHref = "/favicon.ico"
 }
@@ -134,19 +147,125 @@ func (builder *HTMLBuilder) SetOGImage(filename string) {
 	builder.ogImageURL = builder.siteDomain + "/" + filename
 }
 
-func (builder *HTMLBuilder) GenerateHTML(urlPath string, src io.Reader, dst io.Writer) error {
-	var matter struct {
-		Title string `yaml:"title"`
+func (builder *HTMLBuilder) EnableLiveReload(endpoint string) {
+	builder.liveReloadSrc = endpoint
+}
+
+// EnableAssetHashing replaces the filenames of files referenced by pages with their hash.
+func (builder *HTMLBuilder) EnableAssetHashing() {
+	builder.assetHashing = true
+}
+
+func (builder *HTMLBuilder) AddScript(filename string) {
+	builder.scriptSrc = append(builder.scriptSrc, "/"+filename)
+}
+
+func (builder *HTMLBuilder) EnableSearch(indexFilename string, scriptFilename string) {
+	builder.searchIndexSrc = "/" + indexFilename
+	builder.searchScriptSrc = "/" + scriptFilename
+}
+
+func (builder *HTMLBuilder) EnableCopyButton() {
+	builder.copyButton = true
+}
+
+func (builder *HTMLBuilder) SetMarkdownConfig(config MarkdownConfig) {
+	builder.markdown = newMarkdown(config)
+}
+
+// SetDiagramRenderer renders code blocks of the language to SVG images when building pages.
+func (builder *HTMLBuilder) SetDiagramRenderer(language string, renderer DiagramRenderer) {
+	if builder.diagramRenderers == nil {
+		builder.diagramRenderers = map[string]DiagramRenderer{}
+	}
+	builder.diagramRenderers[language] = renderer
+}
+
+// SetMermaidScript sets the script that renders mermaid code blocks in the browser.
+func (builder *HTMLBuilder) SetMermaidScript(config MermaidConfig) {
+	builder.mermaidScript = config
+}
+
+func (builder *HTMLBuilder) GenerateHTML(urlPath string, markdownFilePath string, src io.Reader, dst io.Writer) error {
+	var matter pageMatter
+
+	file, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+	for _, frontmatterError := range validatePageMatter(markdownFilePath, file) {
+		if !frontmatterError.Warning {
+			return frontmatterError
+		}
+	}
+	pageMarkdown, err := frontmatter.Parse(bytes.NewReader(file), &matter)
+	if err != nil {
+		return err
+	}
+	lineOffset := bytes.Count(file[:len(file)-len(pageMarkdown)], []byte("\n"))
+
+	pc := parser.NewContext()
+	pc.Set(markdownFilePathContextKey, markdownFilePath)
+	pc.Set(diagramRenderersContextKey, builder.diagramRenderers)
+	document := builder.markdown.Parser().Parse(text.NewReader(pageMarkdown), parser.WithContext(pc))
+	if err := builder.resolvePageFiles(pc, pageMarkdown); err != nil {
+		return err
+	}
+	if err := getPageError(markdownFilePath, pc, lineOffset); err != nil {
+		return err
+	}
+
+	description := matter.Description
+	if description == "" {
+		description = builder.siteDescription
+	}
+	ogImageURL := builder.ogImageURL
+	if matter.Image != "" {
+		ogImageURL, err = builder.resolvePageImageURL(markdownFilePath, matter.Image)
+		if err != nil {
+			return &FrontmatterError{markdownFilePath, getFrontmatterLine(file, "image"), err.Error(), false}
+		}
+	}
+	canonical := builder.siteDomain + urlPath
+	if matter.Canonical != "" {
+		canonical = builder.absoluteURL(matter.Canonical)
+	}
+	keywords := matter.Keywords
+	if len(keywords) == 0 {
+		keywords = builder.siteKeywords
+	}
+	var mermaidScript MermaidConfig
+	if mermaidDiagrams, _ := pc.Get(mermaidDiagramsContextKey).(bool); mermaidDiagrams {
+		mermaidScript = builder.mermaidScript
+	}
+	// drafts are only rendered by the dev server and by builds with drafts included
+	now := time.Now()
+	draft := matter.unpublished(now)
+	var publishDate string
+	if matter.scheduled(now) {
+		publishDate = matter.PublishDate
+	}
+
+	var toc []TocHeading
+	if matter.Toc == nil || *matter.Toc {
+		tocDepth := matter.TocDepth
+		if tocDepth == 0 {
+			tocDepth = defaultTocDepth
+		}
+		toc = collectTocHeadings(document, pageMarkdown, tocDepth)
 	}
 
-	pageMarkdown, _ := frontmatter.MustParse(src, &matter)
-	if matter.Title == "" {
-		return &MissingAttributeError{"title"}
+	var prevPage, nextPage *NavPage
+	if matter.Pagination == nil || *matter.Pagination {
+		prevPage, nextPage, err = builder.getPaginationLinks(urlPath, matter.Prev, matter.Next)
+		if err != nil {
+			return err
+		}
 	}
 
 	var markdownHtmlBuf bytes.Buffer
 
-	if err := markdown.Convert(pageMarkdown, &markdownHtmlBuf, parser.WithContext(parser.NewContext())); err != nil {
+	if err := builder.markdown.Renderer().Render(&markdownHtmlBuf, pageMarkdown, document); err != nil {
 		panic(err)
 	}
 
@@ -155,36 +274,55 @@ func (builder *HTMLBuilder) GenerateHTML(urlPath string, src io.Reader, dst io.W
 	markdownHtml = strings.ReplaceAll(markdownHtml, "</table>", "</table></div>")
 
 	currentNavPageHref, _ := matchClosestPage(builder.navSections, urlPath)
-	err := tmpl.Execute(dst, Data{
+	err = builder.tmpl.ExecuteTemplate(dst, "template.html", Data{
 		Markdown:           template.HTML(markdownHtml),
 		Name:               builder.siteName,
-		Description:        builder.siteDescription,
+		Description:        description,
 		Url:                builder.siteDomain + urlPath,
+		Canonical:          canonical,
+		Noindex:            matter.Noindex || draft,
+		Keywords:           keywords,
+		Draft:              draft,
+		PublishDate:        publishDate,
 		Twitter:            builder.siteTwitterHandle,
 		Title:              matter.Title,
+		Toc:                toc,
+		PrevPage:           prevPage,
+		NextPage:           nextPage,
 		NavSections:        builder.navSections,
 		CurrentNavPageHref: currentNavPageHref,
 		LogoImageSrc:       builder.logoImageSrc,
-		OGImageURL:         builder.ogImageURL,
+		OGImageURL:         ogImageURL,
 		FaviconHref:        builder.faviconHref,
 		Stylesheets:        builder.styleSheetSrc,
+		Scripts:            builder.scriptSrc,
+		LiveReloadSrc:      builder.liveReloadSrc,
+		SearchIndexSrc:     builder.searchIndexSrc,
+		SearchScriptSrc:    builder.searchScriptSrc,
+		CopyButton:         builder.copyButton,
+		MermaidSrc:         mermaidScript.Src,
+		MermaidIntegrity:   mermaidScript.Integrity,
 	})
 	return err
 }
 
 func (builder *HTMLBuilder) Generate404HTML(dst io.Writer) error {
-	err := tmpl.Execute(dst, Data{
-		Markdown:     template.HTML("<h1>404 - Not found</h1><p>The page you were looking for does not exist.</p>"),
-		Name:         builder.siteName,
-		Description:  builder.siteDescription,
-		Url:          builder.siteDomain + "/404",
-		Twitter:      builder.siteTwitterHandle,
-		Title:        "Not found",
-		NavSections:  builder.navSections,
-		LogoImageSrc: builder.logoImageSrc,
-		OGImageURL:   builder.ogImageURL,
-		FaviconHref:  builder.faviconHref,
-		Stylesheets:  builder.styleSheetSrc,
+	err := builder.tmpl.ExecuteTemplate(dst, "template.html", Data{
+		Markdown:        template.HTML("<h1>404 - Not found</h1><p>The page you were looking for does not exist.</p>"),
+		Name:            builder.siteName,
+		Description:     builder.siteDescription,
+		Url:             builder.siteDomain + "/404",
+		Twitter:         builder.siteTwitterHandle,
+		Title:           "Not found",
+		NavSections:     builder.navSections,
+		LogoImageSrc:    builder.logoImageSrc,
+		OGImageURL:      builder.ogImageURL,
+		FaviconHref:     builder.faviconHref,
+		Stylesheets:     builder.styleSheetSrc,
+		Scripts:         builder.scriptSrc,
+		LiveReloadSrc:   builder.liveReloadSrc,
+		SearchIndexSrc:  builder.searchIndexSrc,
+		SearchScriptSrc: builder.searchScriptSrc,
 	})
 	return err
 }
@@ -197,42 +335,99 @@ func (e *MissingAttributeError) Error() string {
 	return fmt.Sprintf("missing attributes: %s", e.Attribute)
 }
 
+type InvalidAttributeError struct {
+	Attribute string
+	Message   string
+}
+
+func (e *InvalidAttributeError) Error() string {
+	return fmt.Sprintf("invalid attribute: %s: %s", e.Attribute, e.Message)
+}
+
 type NavSection struct {
 	Title string
+	Href  string
 	Pages []NavPage
 }
 
+// NavPage is a link in the sidebar.
+// A page with nested pages is rendered as a collapsible group, and Href may be empty.
 type NavPage struct {
 	Title string
 	Href  string
+	Pages []NavPage
+}
+
+func (page NavPage) Contains(href string) bool {
+	for _, child := range page.Pages {
+		if child.Href == href || child.Contains(href) {
+			return true
+		}
+	}
+	return false
+}
+
+func flattenNavPages(pages []NavPage) []NavPage {
+	var flattened []NavPage
+	for _, page := range pages {
+		flattened = append(flattened, page)
+		flattened = append(flattened, flattenNavPages(page.Pages)...)
+	}
+	return flattened
+}
+
+type navPagesData struct {
+	Pages              []NavPage
+	CurrentNavPageHref string
 }
 
 type Data struct {
 	Markdown           template.HTML
 	Title              string
+	Toc                []TocHeading
+	PrevPage           *NavPage
+	NextPage           *NavPage
 	Description        string
 	Twitter            string
 	Url                string
+	Canonical          string
+	Noindex            bool
+	Keywords           []string
+	Draft              bool
+	PublishDate        string
 	Name               string
 	NavSections        []NavSection
 	CurrentNavPageHref string
 	LogoImageSrc       string
 	OGImageURL         string
 	Stylesheets        []string
+	Scripts            []string
 	FaviconHref        string
+	LiveReloadSrc      string
+	SearchIndexSrc     string
+	SearchScriptSrc    string
+	CopyButton         bool
+	MermaidSrc         string
+	MermaidIntegrity   string
 }
 
 func ParseConfigFile() (ProjectConfig, error) {
 	var unmarshalledConfig struct {
-		Name          string `json:"name"`
-		Description   string `json:"description"`
-		Domain        string `json:"domain"`
-		TwitterHandle string `json:"twitter"`
-		Sidebar       []struct {
-			Title string     `json:"title"`
-			Pages [][]string `json:"pages"`
-		} `json:"sidebar"`
-		AssetHashing bool `json:"asset_hashing"`
+		Name           string              `json:"name"`
+		Description    string              `json:"description"`
+		Domain         string              `json:"domain"`
+		TwitterHandle  string              `json:"twitter"`
+		Keywords       []string            `json:"keywords"`
+		Sidebar        json.RawMessage     `json:"sidebar"`
+		AssetHashing   bool                `json:"asset_hashing"`
+		SitemapLastMod string              `json:"sitemap_lastmod"`
+		CSS            []string            `json:"css"`
+		Scripts        []string            `json:"scripts"`
+		Markdown       json.RawMessage     `json:"markdown"`
+		Highlight      json.RawMessage     `json:"highlight"`
+		CopyButton     *bool               `json:"copy_button"`
+		Diagrams       map[string][]string `json:"diagrams"`
+		Mermaid        json.RawMessage     `json:"mermaid"`
 	}
 	var config ProjectConfig
 
@@ -266,24 +461,194 @@ func ParseConfigFile() (ProjectConfig, error) {
 
 	config.TwitterHandle = unmarshalledConfig.TwitterHandle
 
-	for _, sidebarSection := range unmarshalledConfig.Sidebar {
-		navSection := NavSection{Title: sidebarSection.Title, Pages: []NavPage{}}
-		for _, sidebarSectionPage := range sidebarSection.Pages {
-			navPage := NavPage{Title: sidebarSectionPage[0], Href: sidebarSectionPage[1]}
-			navSection.Pages = append(navSection.Pages, navPage)
+	config.Keywords = unmarshalledConfig.Keywords
+
+	config.AssetHashing = unmarshalledConfig.AssetHashing
+
+	if unmarshalledConfig.SitemapLastMod != "" && unmarshalledConfig.SitemapLastMod != "mtime" && unmarshalledConfig.SitemapLastMod != "git" {
+		return config, &InvalidConfigError{Field: "sitemap_lastmod", Message: "expected \"mtime\" or \"git\""}
+	}
+	config.SitemapLastMod = unmarshalledConfig.SitemapLastMod
+
+	for _, filename := range unmarshalledConfig.CSS {
+		if !isProjectFilePath(filename) {
+			return config, &InvalidConfigError{Field: "css", Message: fmt.Sprintf("%s must be a path inside the project", filename)}
+		}
+		if isBuiltInAssetFilename(filename) {
+			return config, &InvalidConfigError{Field: "css", Message: fmt.Sprintf("%s conflicts with a built-in asset", filename)}
+		}
+	}
+	config.CSS = unmarshalledConfig.CSS
+	for _, filename := range unmarshalledConfig.Scripts {
+		if !isProjectFilePath(filename) {
+			return config, &InvalidConfigError{Field: "scripts", Message: fmt.Sprintf("%s must be a path inside the project", filename)}
+		}
+		if isBuiltInAssetFilename(filename) {
+			return config, &InvalidConfigError{Field: "scripts", Message: fmt.Sprintf("%s conflicts with a built-in asset", filename)}
+		}
+	}
+	config.Scripts = unmarshalledConfig.Scripts
+
+	config.Markdown = DefaultMarkdownConfig()
+	if len(unmarshalledConfig.Markdown) > 0 {
+		if err := json.Unmarshal(unmarshalledConfig.Markdown, &config.Markdown); err != nil {
+			return config, &InvalidConfigError{Field: "markdown", Message: err.Error()}
+		}
+	}
+
+	config.CopyButton = unmarshalledConfig.CopyButton == nil || *unmarshalledConfig.CopyButton
+
+	config.Highlight = DefaultHighlightConfig()
+	if len(unmarshalledConfig.Highlight) > 0 {
+		if err := json.Unmarshal(unmarshalledConfig.Highlight, &config.Highlight); err != nil {
+			return config, &InvalidConfigError{Field: "highlight", Message: err.Error()}
+		}
+	}
+	for _, style := range []string{config.Highlight.LightStyle, config.Highlight.DarkStyle} {
+		if !isHighlightStyle(style) {
+			return config, &InvalidConfigError{Field: "highlight", Message: fmt.Sprintf("unknown style %s", style)}
+		}
+	}
+
+	for language, command := range unmarshalledConfig.Diagrams {
+		if len(command) == 0 || command[0] == "" {
+			return config, &InvalidConfigError{Field: "diagrams", Message: fmt.Sprintf("missing command for %s", language)}
 		}
-		config.NavSections = append(config.NavSections, navSection)
+	}
+	config.Diagrams = unmarshalledConfig.Diagrams
+
+	config.Mermaid = DefaultMermaidConfig()
+	if len(unmarshalledConfig.Mermaid) > 0 {
+		if err := json.Unmarshal(unmarshalledConfig.Mermaid, &config.Mermaid); err != nil {
+			return config, &InvalidConfigError{Field: "mermaid", Message: err.Error()}
+		}
+	}
+
+	config.NavSections, err = parseSidebarConfig(unmarshalledConfig.Sidebar)
+	if err != nil {
+		return config, err
 	}
 	return config, nil
 }
 
+// parseSidebarConfig accepts either "auto" or a list of sections.
+// A section can define its pages manually or generate them from a directory with "dir".
+func parseSidebarConfig(sidebarJson json.RawMessage) ([]NavSection, error) {
+	if len(sidebarJson) == 0 {
+		return nil, nil
+	}
+	var sidebarMode string
+	if err := json.Unmarshal(sidebarJson, &sidebarMode); err == nil {
+		if sidebarMode != "auto" {
+			return nil, &InvalidConfigError{Field: "sidebar", Message: "expected \"auto\" or a list of sections"}
+		}
+		return generateNavSections()
+	}
+
+	var sidebar []struct {
+		Title string            `json:"title"`
+		Dir   string            `json:"dir"`
+		Pages []json.RawMessage `json:"pages"`
+	}
+	if err := json.Unmarshal(sidebarJson, &sidebar); err != nil {
+		return nil, &InvalidConfigError{Field: "sidebar", Message: err.Error()}
+	}
+	navSections := []NavSection{}
+	for _, sidebarSection := range sidebar {
+		if sidebarSection.Dir != "" {
+			navSection, _, err := generateNavSection(filepath.Join("pages", filepath.FromSlash(sidebarSection.Dir)))
+			if err != nil {
+				return nil, err
+			}
+			if sidebarSection.Title != "" {
+				navSection.Title = sidebarSection.Title
+			}
+			navSections = append(navSections, navSection)
+			continue
+		}
+		navPages, err := parseSidebarPagesConfig(sidebarSection.Pages)
+		if err != nil {
+			return nil, err
+		}
+		navSections = append(navSections, NavSection{Title: sidebarSection.Title, Pages: navPages})
+	}
+	return navSections, nil
+}
+
+// parseSidebarPagesConfig parses a list of pages, where each page is either
+// a [title, href] pair or a group object with its own list of pages.
+func parseSidebarPagesConfig(pagesJson []json.RawMessage) ([]NavPage, error) {
+	navPages := []NavPage{}
+	for _, pageJson := range pagesJson {
+		var sidebarPage []string
+		if err := json.Unmarshal(pageJson, &sidebarPage); err == nil {
+			if len(sidebarPage) != 2 {
+				return nil, &InvalidConfigError{Field: "sidebar", Message: "pages must be [title, href] pairs"}
+			}
+			navPages = append(navPages, NavPage{Title: sidebarPage[0], Href: sidebarPage[1]})
+			continue
+		}
+		var sidebarGroup struct {
+			Title string            `json:"title"`
+			Href  string            `json:"href"`
+			Pages []json.RawMessage `json:"pages"`
+		}
+		if err := json.Unmarshal(pageJson, &sidebarGroup); err != nil {
+			return nil, &InvalidConfigError{Field: "sidebar", Message: "pages must be [title, href] pairs or groups"}
+		}
+		if sidebarGroup.Title == "" {
+			return nil, &InvalidConfigError{Field: "sidebar", Message: "groups must have a title"}
+		}
+		groupPages, err := parseSidebarPagesConfig(sidebarGroup.Pages)
+		if err != nil {
+			return nil, err
+		}
+		navPages = append(navPages, NavPage{Title: sidebarGroup.Title, Href: sidebarGroup.Href, Pages: groupPages})
+	}
+	return navPages, nil
+}
+
 type ProjectConfig struct {
-	Name          string
-	Description   string
-	Domain        string
-	TwitterHandle string
-	NavSections   []NavSection
-	AssetHashing  bool
+	Name           string
+	Description    string
+	Domain         string
+	TwitterHandle  string
+	Keywords       []string
+	NavSections    []NavSection
+	AssetHashing   bool
+	SitemapLastMod string
+	CSS            []string
+	Scripts        []string
+	Markdown       MarkdownConfig
+	Highlight      HighlightConfig
+	CopyButton     bool
+	// commands rendering code blocks to SVG images, by language
+	Diagrams map[string][]string
+	Mermaid  MermaidConfig
+}
+
+func isProjectFilePath(filename string) bool {
+	if filename == "" || filepath.IsAbs(filename) {
+		return false
+	}
+	cleaned := filepath.ToSlash(filepath.Clean(filename))
+	return cleaned != ".." && !strings.HasPrefix(cleaned, "../")
+}
+
+// isBuiltInAssetFilename reports whether a project file would be written over
+// a built-in asset or a generated file.
+func isBuiltInAssetFilename(filename string) bool {
+	cleaned := filepath.ToSlash(filepath.Clean(filename))
+	if cleaned == "highlight.css" || cleaned == "search-index.json" {
+		return true
+	}
+	assetFilenames, _ := GetAssetFilenames()
+	for _, assetFilename := range assetFilenames {
+		if cleaned == assetFilename {
+			return true
+		}
+	}
+	return false
 }
 
 type MissingConfigFileError struct {
@@ -294,13 +659,43 @@ func (e *MissingConfigFileError) Error() string {
 }
 
 type InvalidConfigError struct {
-	Field string
+	Field   string
+	Message string
 }
 
 func (e *InvalidConfigError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("invalid config: %s: %s", e.Field, e.Message)
+	}
 	return fmt.Sprintf("missing config: %s", e.Field)
 }
 
+// GetMarkdownFilePaths returns the paths of all markdown files inside the pages directory.
+func GetMarkdownFilePaths() ([]string, error) {
+	var markdownFilePaths []string
+	err := filepath.Walk("pages", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && filepath.Ext(path) == ".md" {
+			markdownFilePaths = append(markdownFilePaths, path)
+		}
+		return nil
+	})
+	return markdownFilePaths, err
+}
+
+// GetPageURLPath returns the URL pathname of a markdown file inside the pages directory.
+func GetPageURLPath(markdownFilePath string) string {
+	urlPath := strings.TrimPrefix(filepath.ToSlash(markdownFilePath), "pages")
+	urlPath = strings.TrimSuffix(urlPath, ".md")
+	urlPath = strings.TrimSuffix(urlPath, "/index")
+	if urlPath == "" {
+		urlPath = "/"
+	}
+	return urlPath
+}
+
 func ParseURLPath(p string) []string {
 	if len(p) < 1 {
 		panic("invalid path")
@@ -327,7 +722,7 @@ func matchClosestPage(sections []NavSection, target string) (string, bool) {
 	var sameDepthDuplicate int
 	targetParts := ParseURLPath(target)
 	for _, section := range sections {
-		for _, page := range section.Pages {
+		for _, page := range flattenNavPages(section.Pages) {
 			if target == page.Href {
 				return page.Href, true
 			}
@@ -0,0 +1,63 @@
+package build
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// MarkdownConfig toggles the optional markdown syntaxes.
+// Every syntax except math is enabled by default,
+// since math would change the meaning of existing text with dollar signs.
+type MarkdownConfig struct {
+	Tables          bool `json:"tables"`
+	Strikethrough   bool `json:"strikethrough"`
+	TaskLists       bool `json:"task_lists"`
+	Autolinks       bool `json:"autolinks"`
+	Footnotes       bool `json:"footnotes"`
+	DefinitionLists bool `json:"definition_lists"`
+	Math            bool `json:"math"`
+}
+
+func DefaultMarkdownConfig() MarkdownConfig {
+	return MarkdownConfig{
+		Tables:          true,
+		Strikethrough:   true,
+		TaskLists:       true,
+		Autolinks:       true,
+		Footnotes:       true,
+		DefinitionLists: true,
+		Math:            false,
+	}
+}
+
+func newMarkdown(config MarkdownConfig) goldmark.Markdown {
+	extensions := []goldmark.Extender{&containerExtension{}}
+	if config.Tables {
+		extensions = append(extensions, extension.Table)
+	}
+	if config.Strikethrough {
+		extensions = append(extensions, extension.Strikethrough)
+	}
+	if config.TaskLists {
+		extensions = append(extensions, extension.TaskList)
+	}
+	if config.Autolinks {
+		extensions = append(extensions, extension.Linkify)
+	}
+	if config.Footnotes {
+		extensions = append(extensions, extension.Footnote)
+	}
+	if config.DefinitionLists {
+		extensions = append(extensions, extension.DefinitionList)
+	}
+	if config.Math {
+		extensions = append(extensions, &mathExtension{})
+	}
+	md := goldmark.New(goldmark.WithExtensions(extensions...))
+	md.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(&codeBlockLinksAstTransformer{}, 500), util.Prioritized(&relativeLinksAstTransformer{}, 500)), parser.WithAutoHeadingID())
+	md.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&codeBlockLinksRenderer{}, 100)))
+	return md
+}
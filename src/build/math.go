@@ -0,0 +1,222 @@
+package build
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// convertMath converts the TeX expression starting at offset in source to MathML.
+// Errors are stored in the parser context so that pages are still parsed to the end.
+func convertMath(tex string, display bool, source []byte, offset int, pc parser.Context) string {
+	mathml, err := texToMathML(tex, display)
+	if err == nil {
+		return mathml
+	}
+	texErr := err.(*TexError)
+	line := bytes.Count(source[:offset], []byte("\n")) + 1 + bytes.Count([]byte(tex[:texErr.Offset]), []byte("\n"))
+	addPageIssue(pc, line, fmt.Sprintf("invalid math: %s", texErr.Message))
+	return ""
+}
+
+var KindMath = ast.NewNodeKind("Math")
+
+// Math is an inline "$...$" expression, or a "$$...$$" expression inside a paragraph.
+// Its child is the TeX source, used as the text of headings and search results.
+type Math struct {
+	ast.BaseInline
+	Display bool
+	MathML  string
+}
+
+func (n *Math) Kind() ast.NodeKind {
+	return KindMath
+}
+
+func (n *Math) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Display": fmt.Sprint(n.Display)}, nil)
+}
+
+var KindMathBlock = ast.NewNodeKind("MathBlock")
+
+// MathBlock is a "$$" block, which can span multiple lines.
+type MathBlock struct {
+	ast.BaseBlock
+	MathML string
+	// offset of the opening "$$" in the source
+	start int
+	// the block was opened and closed on the same line
+	closed bool
+}
+
+func (n *MathBlock) Kind() ast.NodeKind {
+	return KindMathBlock
+}
+
+func (n *MathBlock) IsRaw() bool {
+	return true
+}
+
+func (n *MathBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// mathExtension adds TeX math expressions, which are converted to MathML at build time.
+type mathExtension struct{}
+
+func (e mathExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(util.Prioritized(&mathBlockParser{}, 750)),
+		parser.WithInlineParsers(util.Prioritized(&mathInlineParser{}, 150)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&mathRenderer{}, 100)))
+}
+
+// mathInlineParser parses "$...$" and "$$...$$" on a single line.
+// Like in Pandoc, the opening "$" must be followed by a non-space character,
+// and the closing "$" must follow a non-space character and must not be followed by a digit,
+// so that amounts like "$5 and $10" are left as is.
+// "\$" is a literal dollar sign.
+type mathInlineParser struct{}
+
+func (p mathInlineParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (p mathInlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	display := len(line) > 1 && line[1] == '$'
+	delimiterLength := 1
+	if display {
+		delimiterLength = 2
+	}
+	end := -1
+	for i := delimiterLength; i < len(line) && end < 0; i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '$':
+			if display {
+				if i+1 < len(line) && line[i+1] == '$' {
+					end = i
+				}
+			} else if !util.IsSpace(line[i-1]) && (i+1 >= len(line) || !isASCIIDigit(line[i+1])) {
+				end = i
+			}
+		}
+	}
+	if end < 0 {
+		return nil
+	}
+	content := line[delimiterLength:end]
+	if len(bytes.TrimSpace(content)) == 0 || (!display && util.IsSpace(content[0])) {
+		return nil
+	}
+	texSegment := text.NewSegment(segment.Start+delimiterLength, segment.Start+end)
+	node := &Math{Display: display}
+	node.MathML = convertMath(string(content), display, block.Source(), texSegment.Start, pc)
+	node.AppendChild(node, ast.NewTextSegment(texSegment))
+	block.Advance(end + delimiterLength)
+	return node
+}
+
+// mathBlockParser parses blocks starting with "$$" and ending with "$$".
+type mathBlockParser struct{}
+
+func (b mathBlockParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (b mathBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 || !bytes.HasPrefix(line[pos:], []byte("$$")) {
+		return nil, parser.NoChildren
+	}
+	start := segment.Start + pos
+	rest := line[pos+2:]
+	node := &MathBlock{start: start}
+	if end := bytes.Index(rest, []byte("$$")); end >= 0 {
+		// "$$...$$" followed by text is inline
+		if !util.IsBlank(rest[end+2:]) {
+			return nil, parser.NoChildren
+		}
+		node.Lines().Append(text.NewSegment(start+2, start+2+end))
+		node.closed = true
+		reader.Advance(segment.Len() - util.TrimRightSpaceLength(line))
+		return node, parser.NoChildren
+	}
+	if !util.IsBlank(rest) {
+		node.Lines().Append(text.NewSegment(start+2, segment.Stop))
+	}
+	reader.Advance(segment.Len() - util.TrimRightSpaceLength(line))
+	return node, parser.NoChildren
+}
+
+func (b mathBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	if node.(*MathBlock).closed {
+		return parser.Close
+	}
+	line, segment := reader.PeekLine()
+	if end := bytes.Index(line, []byte("$$")); end >= 0 {
+		if !util.IsBlank(line[:end]) {
+			node.Lines().Append(text.NewSegment(segment.Start, segment.Start+end))
+		}
+		reader.Advance(segment.Len() - util.TrimRightSpaceLength(line))
+		return parser.Close
+	}
+	node.Lines().Append(segment)
+	reader.Advance(segment.Len() - 1)
+	return parser.Continue | parser.NoChildren
+}
+
+func (b mathBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+	mathBlock := node.(*MathBlock)
+	source := reader.Source()
+	var tex bytes.Buffer
+	for i := 0; i < node.Lines().Len(); i++ {
+		line := node.Lines().At(i)
+		tex.Write(line.Value(source))
+	}
+	offset := mathBlock.start
+	if node.Lines().Len() > 0 {
+		offset = node.Lines().At(0).Start
+	}
+	mathBlock.MathML = convertMath(tex.String(), true, source, offset, pc)
+}
+
+func (b mathBlockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (b mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+type mathRenderer struct{}
+
+func (r mathRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindMath, r.renderMath)
+	reg.Register(KindMathBlock, r.renderMathBlock)
+}
+
+func (r mathRenderer) renderMath(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.WriteString(node.(*Math).MathML)
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (r mathRenderer) renderMathBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.WriteString(node.(*MathBlock).MathML)
+		w.WriteString("\n")
+	}
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,284 @@
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/adrg/frontmatter"
+	"gopkg.in/yaml.v2"
+)
+
+// pageMatter holds the frontmatter attributes of a page.
+type pageMatter struct {
+	Title           string      `yaml:"title"`
+	SidebarLabel    string      `yaml:"sidebar_label"`
+	SidebarPosition *int        `yaml:"sidebar_position"`
+	Toc             *bool       `yaml:"toc"`
+	TocDepth        int         `yaml:"toc_depth"`
+	Pagination      *bool       `yaml:"pagination"`
+	Prev            interface{} `yaml:"prev"`
+	Next            interface{} `yaml:"next"`
+	Sitemap         *bool       `yaml:"sitemap"`
+	Description     string      `yaml:"description"`
+	Image           string      `yaml:"image"`
+	Canonical       string      `yaml:"canonical"`
+	Noindex         bool        `yaml:"noindex"`
+	Keywords        []string    `yaml:"keywords"`
+	Draft           bool        `yaml:"draft"`
+	PublishDate     string      `yaml:"publish_date"`
+}
+
+// readPageMatter returns the frontmatter attributes of the page.
+// Invalid frontmatter is ignored here and reported by ValidatePageMatter,
+// so that every page can be checked in a single run.
+func readPageMatter(markdownFilePath string) (pageMatter, error) {
+	var matter pageMatter
+	file, err := os.ReadFile(markdownFilePath)
+	if err != nil {
+		return matter, err
+	}
+	frontmatter.Parse(bytes.NewReader(file), &matter)
+	return matter, nil
+}
+
+// FrontmatterError is an invalid frontmatter attribute.
+// Warnings don't fail the build.
+type FrontmatterError struct {
+	FilePath string
+	Line     int
+	Message  string
+	Warning  bool
+}
+
+func (e *FrontmatterError) Error() string {
+	if e.Warning {
+		return fmt.Sprintf("%s:%d: warning: %s", filepath.ToSlash(e.FilePath), e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", filepath.ToSlash(e.FilePath), e.Line, e.Message)
+}
+
+type frontmatterType int
+
+const (
+	frontmatterString frontmatterType = iota
+	frontmatterInteger
+	frontmatterBoolean
+	frontmatterStringList
+	frontmatterHrefOrBoolean
+	frontmatterDate
+)
+
+func (t frontmatterType) String() string {
+	switch t {
+	case frontmatterInteger:
+		return "integer"
+	case frontmatterBoolean:
+		return "boolean"
+	case frontmatterStringList:
+		return "list of strings"
+	case frontmatterHrefOrBoolean:
+		return "href or boolean"
+	case frontmatterDate:
+		return "date (YYYY-MM-DD)"
+	}
+	return "string"
+}
+
+// the type of each attribute of pageMatter
+var frontmatterSchema = map[string]frontmatterType{
+	"title":            frontmatterString,
+	"sidebar_label":    frontmatterString,
+	"sidebar_position": frontmatterInteger,
+	"toc":              frontmatterBoolean,
+	"toc_depth":        frontmatterInteger,
+	"pagination":       frontmatterBoolean,
+	"prev":             frontmatterHrefOrBoolean,
+	"next":             frontmatterHrefOrBoolean,
+	"sitemap":          frontmatterBoolean,
+	"description":      frontmatterString,
+	"image":            frontmatterString,
+	"canonical":        frontmatterString,
+	"noindex":          frontmatterBoolean,
+	"keywords":         frontmatterStringList,
+	"draft":            frontmatterBoolean,
+	"publish_date":     frontmatterDate,
+}
+
+// matchesFrontmatterType reports whether the decoded YAML value can be used as an attribute of the type.
+// Like when decoding, any scalar is accepted as a string.
+func matchesFrontmatterType(value interface{}, t frontmatterType) bool {
+	if value == nil {
+		return true
+	}
+	switch t {
+	case frontmatterString:
+		return isYAMLScalar(value)
+	case frontmatterInteger:
+		_, ok := value.(int)
+		return ok
+	case frontmatterBoolean:
+		_, ok := value.(bool)
+		return ok
+	case frontmatterStringList:
+		items, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, item := range items {
+			if !isYAMLScalar(item) {
+				return false
+			}
+		}
+		return true
+	case frontmatterHrefOrBoolean:
+		switch value.(type) {
+		case string, bool:
+			return true
+		}
+	case frontmatterDate:
+		date, ok := value.(string)
+		if !ok {
+			return false
+		}
+		_, err := parsePublishDate(date)
+		return err == nil
+	}
+	return false
+}
+
+func isYAMLScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, int, float64, bool:
+		return true
+	}
+	return false
+}
+
+// yamlTypeName returns the name of the type of a decoded YAML value used in error messages.
+func yamlTypeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case int:
+		return "integer"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "list"
+	}
+	return "map"
+}
+
+var yamlErrorLineRegex = regexp.MustCompile(`^yaml: line (\d+): `)
+
+// ValidatePageMatter checks the frontmatter of every page against the known attributes,
+// and returns all the errors and warnings found.
+func ValidatePageMatter(markdownFilePaths []string) ([]*FrontmatterError, error) {
+	var frontmatterErrors []*FrontmatterError
+	for _, markdownFilePath := range markdownFilePaths {
+		file, err := os.ReadFile(markdownFilePath)
+		if err != nil {
+			return nil, err
+		}
+		frontmatterErrors = append(frontmatterErrors, validatePageMatter(markdownFilePath, file)...)
+	}
+	return frontmatterErrors, nil
+}
+
+// validatePageMatter checks the YAML frontmatter of the page.
+// Unknown attributes are warnings, and attributes of the wrong type and a missing title are errors.
+func validatePageMatter(markdownFilePath string, file []byte) []*FrontmatterError {
+	content, ok := getYAMLFrontmatter(file)
+	if !ok {
+		return validateDecodedPageMatter(markdownFilePath, file)
+	}
+	var attributes yaml.MapSlice
+	if err := yaml.Unmarshal(content, &attributes); err != nil {
+		message := strings.TrimPrefix(err.Error(), "yaml: ")
+		line := 1
+		if match := yamlErrorLineRegex.FindStringSubmatch(err.Error()); match != nil {
+			// the frontmatter starts after the "---" line
+			line, _ = strconv.Atoi(match[1])
+			line++
+			message = strings.TrimPrefix(err.Error(), match[0])
+		}
+		return []*FrontmatterError{{markdownFilePath, line, fmt.Sprintf("invalid frontmatter: %s", message), false}}
+	}
+	var frontmatterErrors []*FrontmatterError
+	hasTitle := false
+	for _, attribute := range attributes {
+		key := fmt.Sprint(attribute.Key)
+		line := getFrontmatterLine(file, key)
+		t, ok := frontmatterSchema[key]
+		if !ok {
+			frontmatterErrors = append(frontmatterErrors, &FrontmatterError{markdownFilePath, line, fmt.Sprintf("unknown attribute: %s", key), true})
+			continue
+		}
+		if !matchesFrontmatterType(attribute.Value, t) {
+			message := (&InvalidAttributeError{key, fmt.Sprintf("expected %s, got %s", t, yamlTypeName(attribute.Value))}).Error()
+			frontmatterErrors = append(frontmatterErrors, &FrontmatterError{markdownFilePath, line, message, false})
+			// a title of the wrong type is not also reported as missing
+			hasTitle = hasTitle || key == "title"
+			continue
+		}
+		if key == "title" && attribute.Value != nil && fmt.Sprint(attribute.Value) != "" {
+			hasTitle = true
+		}
+	}
+	if !hasTitle {
+		frontmatterErrors = append(frontmatterErrors, &FrontmatterError{markdownFilePath, getFrontmatterLine(file, "title"), (&MissingAttributeError{"title"}).Error(), false})
+	}
+	return frontmatterErrors
+}
+
+// validateDecodedPageMatter checks frontmatter in the other formats accepted by adrg/frontmatter, like TOML and JSON,
+// by decoding it into pageMatter. Only decoding errors and a missing title are reported.
+func validateDecodedPageMatter(markdownFilePath string, file []byte) []*FrontmatterError {
+	var matter pageMatter
+	if _, err := frontmatter.Parse(bytes.NewReader(file), &matter); err != nil {
+		return []*FrontmatterError{{markdownFilePath, 1, fmt.Sprintf("invalid frontmatter: %v", err), false}}
+	}
+	if matter.Title == "" {
+		return []*FrontmatterError{{markdownFilePath, 1, (&MissingAttributeError{"title"}).Error(), false}}
+	}
+	return nil
+}
+
+// getYAMLFrontmatter returns the content between the "---" (or "---yaml") and "---" lines at the start of the page.
+func getYAMLFrontmatter(file []byte) ([]byte, bool) {
+	lines := bytes.SplitAfter(file, []byte("\n"))
+	if len(lines) == 0 {
+		return nil, false
+	}
+	if start := bytes.TrimSpace(lines[0]); !bytes.Equal(start, []byte("---")) && !bytes.Equal(start, []byte("---yaml")) {
+		return nil, false
+	}
+	for i := 1; i < len(lines); i++ {
+		if bytes.Equal(bytes.TrimSpace(lines[i]), []byte("---")) {
+			return bytes.Join(lines[1:i], nil), true
+		}
+	}
+	return nil, false
+}
+
+// getFrontmatterLine returns the 1-indexed line of the frontmatter attribute inside the page,
+// or the first line if the attribute is not defined at the top level.
+func getFrontmatterLine(file []byte, key string) int {
+	lines := bytes.Split(file, []byte("\n"))
+	for i, line := range lines {
+		if i > 0 && bytes.Equal(bytes.TrimSpace(line), []byte("---")) {
+			break
+		}
+		if bytes.HasPrefix(line, []byte(key+":")) {
+			return i + 1
+		}
+	}
+	return 1
+}
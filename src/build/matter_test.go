@@ -0,0 +1,58 @@
+package build
+
+import (
+	"testing"
+)
+
+func TestValidatePageMatter(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected []string
+	}{
+		{"---\ntitle: \"A\"\n---\n", nil},
+		{"---\ntitle: \"A\"\nauthor: \"B\"\n---\n", []string{"pages/a.md:3: warning: unknown attribute: author"}},
+		{"---\ntitle: \"A\"\ntoc: \"yes\"\ntoc_depth: 2\n---\n", []string{"pages/a.md:3: invalid attribute: toc: expected boolean, got string"}},
+		{"---\ntitle: \"A\"\nkeywords: [\"a\"\n---\n", []string{"pages/a.md:3: invalid frontmatter: did not find expected ',' or ']'"}},
+		{"---\ndescription: \"A\"\n---\n", []string{"pages/a.md:1: missing attributes: title"}},
+		{"---\nauthor: \"B\"\ntitle: [\"A\"]\n---\n", []string{
+			"pages/a.md:2: warning: unknown attribute: author",
+			"pages/a.md:3: invalid attribute: title: expected string, got list",
+		}},
+	}
+	for _, test := range tests {
+		frontmatterErrors := validatePageMatter("pages/a.md", []byte(test.file))
+		if len(frontmatterErrors) != len(test.expected) {
+			t.Errorf("%q: expected %d errors, got %v", test.file, len(test.expected), frontmatterErrors)
+			continue
+		}
+		for i, frontmatterError := range frontmatterErrors {
+			if frontmatterError.Error() != test.expected[i] {
+				t.Errorf("%q: expected %q, got %q", test.file, test.expected[i], frontmatterError.Error())
+			}
+		}
+	}
+}
+
+func TestValidateDecodedPageMatter(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected []string
+	}{
+		{"+++\ntitle = \"A\"\n+++\n", nil},
+		{"{\n\"title\": \"A\"\n}\n", nil},
+		{"+++\ndescription = \"A\"\n+++\n", []string{"pages/a.md:1: missing attributes: title"}},
+		{"+++\ntitle = \n+++\n", []string{"pages/a.md:1: invalid frontmatter: Near line 1 (last key parsed 'title'): expected value but found '\\n' instead"}},
+	}
+	for _, test := range tests {
+		frontmatterErrors := validatePageMatter("pages/a.md", []byte(test.file))
+		if len(frontmatterErrors) != len(test.expected) {
+			t.Errorf("%q: expected %d errors, got %v", test.file, len(test.expected), frontmatterErrors)
+			continue
+		}
+		for i, frontmatterError := range frontmatterErrors {
+			if frontmatterError.Error() != test.expected[i] {
+				t.Errorf("%q: expected %q, got %q", test.file, test.expected[i], frontmatterError.Error())
+			}
+		}
+	}
+}
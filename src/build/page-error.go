@@ -0,0 +1,49 @@
+package build
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/yuin/goldmark/parser"
+)
+
+// PageError is an error inside a page, reported with the file and line.
+type PageError struct {
+	FilePath string
+	Line     int
+	Message  string
+}
+
+func (e *PageError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", filepath.ToSlash(e.FilePath), e.Line, e.Message)
+}
+
+var pageIssuesContextKey = parser.NewContextKey()
+
+// pageIssue is an error found by a parser or an AST transformer.
+// line is the 1-indexed line inside the markdown, excluding the frontmatter.
+type pageIssue struct {
+	line    int
+	message string
+}
+
+// addPageIssue stores an error in the parser context, so that the page is still parsed to the end.
+func addPageIssue(pc parser.Context, line int, message string) {
+	issues, _ := pc.Get(pageIssuesContextKey).([]pageIssue)
+	pc.Set(pageIssuesContextKey, append(issues, pageIssue{line, message}))
+}
+
+// getPageError returns every error found while parsing the page, sorted by line.
+func getPageError(markdownFilePath string, pc parser.Context, lineOffset int) error {
+	issues, _ := pc.Get(pageIssuesContextKey).([]pageIssue)
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].line < issues[j].line
+	})
+	var pageErrors []error
+	for _, issue := range issues {
+		pageErrors = append(pageErrors, &PageError{markdownFilePath, lineOffset + issue.line, issue.message})
+	}
+	return errors.Join(pageErrors...)
+}
@@ -0,0 +1,28 @@
+package build
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateHTMLPageErrors(t *testing.T) {
+	// every missing file is reported, not only the first one
+	setupTestProject(t, map[string]string{})
+	page := "---\ntitle: \"Home\"\n---\n\n![B](./b.png)\n\n![A](./a.png)\n"
+	builder := NewBuilder("Test", "Test", "https://example.com", nil, nil)
+	var dst bytes.Buffer
+	err := builder.GenerateHTML("/", "pages/index.md", strings.NewReader(page), &dst)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := "pages/index.md:5: missing file: ./b.png\npages/index.md:7: missing file: ./a.png"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+	var pageError *PageError
+	if !errors.As(err, &pageError) || pageError.Line != 5 {
+		t.Errorf("expected the first error to be a PageError at line 5, got %v", err)
+	}
+}
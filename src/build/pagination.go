@@ -0,0 +1,75 @@
+package build
+
+import (
+	"fmt"
+	"strings"
+)
+
+// getPaginationPages returns the internal pages of the sidebar in order,
+// including section landing pages and nested pages.
+func getPaginationPages(sections []NavSection) []NavPage {
+	var pages []NavPage
+	added := make(map[string]bool)
+	addPage := func(page NavPage) {
+		if !strings.HasPrefix(page.Href, "/") || added[page.Href] {
+			return
+		}
+		added[page.Href] = true
+		pages = append(pages, NavPage{Title: page.Title, Href: page.Href})
+	}
+	for _, section := range sections {
+		addPage(NavPage{Title: section.Title, Href: section.Href})
+		for _, page := range flattenNavPages(section.Pages) {
+			addPage(page)
+		}
+	}
+	return pages
+}
+
+// resolvePaginationPage returns the page defined by the prev or next attribute.
+// The attribute can be set to an href or false, and the default page is used when it is not set.
+func resolvePaginationPage(attribute string, value interface{}, defaultPage *NavPage, pages []NavPage) (*NavPage, error) {
+	switch value := value.(type) {
+	case nil:
+		return defaultPage, nil
+	case bool:
+		if value {
+			return defaultPage, nil
+		}
+		return nil, nil
+	case string:
+		for _, page := range pages {
+			if page.Href == value {
+				return &page, nil
+			}
+		}
+		return &NavPage{Title: value, Href: value}, nil
+	}
+	return nil, &InvalidAttributeError{attribute, fmt.Sprintf("expected href or boolean, got %v", value)}
+}
+
+func (builder *HTMLBuilder) getPaginationLinks(urlPath string, prev interface{}, next interface{}) (*NavPage, *NavPage, error) {
+	pages := getPaginationPages(builder.navSections)
+	var defaultPrevPage, defaultNextPage *NavPage
+	for i := range pages {
+		if pages[i].Href != urlPath {
+			continue
+		}
+		if i > 0 {
+			defaultPrevPage = &pages[i-1]
+		}
+		if i+1 < len(pages) {
+			defaultNextPage = &pages[i+1]
+		}
+		break
+	}
+	prevPage, err := resolvePaginationPage("prev", prev, defaultPrevPage, pages)
+	if err != nil {
+		return nil, nil, err
+	}
+	nextPage, err := resolvePaginationPage("next", next, defaultNextPage, pages)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prevPage, nextPage, nil
+}
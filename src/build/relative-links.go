@@ -0,0 +1,176 @@
+package build
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pilcrowOnPaper/malta/utils"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+var markdownFilePathContextKey = parser.NewContextKey()
+var pageFileReferencesContextKey = parser.NewContextKey()
+var writtenDestinationsContextKey = parser.NewContextKey()
+
+// pageFileReference is a relative image or link destination that points to a file inside the pages directory.
+type pageFileReference struct {
+	node        ast.Node
+	destination string
+	filePath    string
+}
+
+// relativeLinksAstTransformer resolves relative image and link destinations against the location
+// of the markdown file, when its path is defined in the parser context.
+// Links to markdown files are replaced with the page URL.
+type relativeLinksAstTransformer struct{}
+
+func (t relativeLinksAstTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	markdownFilePath, ok := pc.Get(markdownFilePathContextKey).(string)
+	if !ok {
+		return
+	}
+	var references []pageFileReference
+	// the destinations before they are resolved, used in error messages
+	writtenDestinations := make(map[ast.Node]string)
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var destination *[]byte
+		switch n := n.(type) {
+		case *ast.Image:
+			destination = &n.Destination
+		case *ast.Link:
+			destination = &n.Destination
+		default:
+			return ast.WalkContinue, nil
+		}
+		resolved, filePath, ok := resolveRelativeDestination(markdownFilePath, string(*destination))
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		if filepath.Ext(filePath) != "" {
+			references = append(references, pageFileReference{n, string(*destination), filePath})
+		}
+		writtenDestinations[n] = string(*destination)
+		*destination = []byte(resolved)
+		return ast.WalkContinue, nil
+	})
+	pc.Set(pageFileReferencesContextKey, references)
+	pc.Set(writtenDestinationsContextKey, writtenDestinations)
+}
+
+// resolveRelativeDestination returns the root-relative URL and the file path of a relative destination.
+// ok is false for absolute URLs, root-relative paths, fragments, and paths outside the pages directory.
+func resolveRelativeDestination(markdownFilePath string, destination string) (string, string, bool) {
+	destinationURL, err := url.Parse(destination)
+	if err != nil || destinationURL.Scheme != "" || destinationURL.Host != "" {
+		return "", "", false
+	}
+	if destinationURL.Path == "" || strings.HasPrefix(destinationURL.Path, "/") {
+		return "", "", false
+	}
+	filePath := filepath.Join(filepath.Dir(markdownFilePath), filepath.FromSlash(destinationURL.Path))
+	relPath, err := filepath.Rel("pages", filePath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", "", false
+	}
+	if filepath.Ext(filePath) == ".md" {
+		destinationURL.Path = GetPageURLPath(filePath)
+	} else {
+		destinationURL.Path = "/" + filepath.ToSlash(relPath)
+	}
+	return destinationURL.String(), filePath, true
+}
+
+// resolvePageFiles checks that the files referenced by the page exist,
+// and registers files other than markdown files to be copied to the output.
+// Missing files are added to the page issues.
+func (builder *HTMLBuilder) resolvePageFiles(pc parser.Context, source []byte) error {
+	references, _ := pc.Get(pageFileReferencesContextKey).([]pageFileReference)
+	for _, reference := range references {
+		info, err := os.Stat(reference.filePath)
+		if err != nil || info.IsDir() {
+			addPageIssue(pc, getNodeLine(reference.node, source), fmt.Sprintf("missing file: %s", reference.destination))
+			continue
+		}
+		if filepath.Ext(reference.filePath) == ".md" {
+			continue
+		}
+		outputFilename, err := builder.addPageFile(reference.filePath)
+		if err != nil {
+			return err
+		}
+		if !builder.assetHashing {
+			continue
+		}
+		resolved := "/" + outputFilename
+		if parsed, err := url.Parse(reference.destination); err == nil && parsed.Fragment != "" {
+			resolved += "#" + parsed.Fragment
+		}
+		switch n := reference.node.(type) {
+		case *ast.Image:
+			n.Destination = []byte(resolved)
+		case *ast.Link:
+			n.Destination = []byte(resolved)
+		}
+	}
+	return nil
+}
+
+// addPageFile registers a file inside the pages directory to be copied to the output,
+// and returns its output filename.
+func (builder *HTMLBuilder) addPageFile(filePath string) (string, error) {
+	if outputFilename, ok := builder.pageFiles[filePath]; ok {
+		return outputFilename, nil
+	}
+	relPath, _ := filepath.Rel("pages", filePath)
+	outputFilename := filepath.ToSlash(relPath)
+	if builder.assetHashing {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return "", err
+		}
+		outputFilename = utils.GetHashedFilename(data, filePath)
+	}
+	builder.pageFiles[filePath] = outputFilename
+	return outputFilename, nil
+}
+
+// resolvePageImageURL returns the absolute URL of the image defined in the frontmatter of a page.
+// Relative paths are resolved against the markdown file, like images inside pages.
+func (builder *HTMLBuilder) resolvePageImageURL(markdownFilePath string, image string) (string, error) {
+	_, filePath, ok := resolveRelativeDestination(markdownFilePath, image)
+	if !ok {
+		return builder.absoluteURL(image), nil
+	}
+	info, err := os.Stat(filePath)
+	if err != nil || info.IsDir() {
+		return "", fmt.Errorf("missing file: %s", image)
+	}
+	outputFilename, err := builder.addPageFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	return builder.siteDomain + "/" + outputFilename, nil
+}
+
+// absoluteURL returns destination as is if it is an absolute URL,
+// and otherwise resolves it against the site domain.
+func (builder *HTMLBuilder) absoluteURL(destination string) string {
+	if parsed, err := url.Parse(destination); err == nil && parsed.IsAbs() {
+		return destination
+	}
+	return builder.siteDomain + "/" + strings.TrimPrefix(destination, "/")
+}
+
+// PageFiles returns the files inside the pages directory referenced by the generated pages,
+// mapped to their output filename.
+func (builder *HTMLBuilder) PageFiles() map[string]string {
+	return builder.pageFiles
+}
@@ -0,0 +1,110 @@
+package build
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/adrg/frontmatter"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+type SearchIndexPage struct {
+	Title    string               `json:"title"`
+	Href     string               `json:"href"`
+	Sections []SearchIndexSection `json:"sections"`
+}
+
+// SearchIndexSection holds the text between a heading and the next one.
+// Id is the heading ID and is empty for text before the first heading.
+type SearchIndexSection struct {
+	Heading string `json:"heading"`
+	Id      string `json:"id"`
+	Text    string `json:"text"`
+}
+
+// GenerateSearchIndex writes the search index of the pages,
+// parsed with the same markdown syntaxes as the generated pages.
+func GenerateSearchIndex(markdownFilePaths []string, markdownConfig MarkdownConfig, dst io.Writer) error {
+	md := newMarkdown(markdownConfig)
+	pages := []SearchIndexPage{}
+	for _, markdownFilePath := range markdownFilePaths {
+		markdownFile, err := os.Open(markdownFilePath)
+		if err != nil {
+			return err
+		}
+		page, err := parseSearchIndexPage(md, GetPageURLPath(markdownFilePath), markdownFile)
+		markdownFile.Close()
+		if err != nil {
+			return err
+		}
+		pages = append(pages, page)
+	}
+	return json.NewEncoder(dst).Encode(pages)
+}
+
+func parseSearchIndexPage(md goldmark.Markdown, urlPath string, src io.Reader) (SearchIndexPage, error) {
+	var matter pageMatter
+	pageMarkdown, err := frontmatter.Parse(src, &matter)
+	if err != nil {
+		return SearchIndexPage{}, err
+	}
+	if matter.Title == "" {
+		return SearchIndexPage{}, &MissingAttributeError{"title"}
+	}
+
+	document := md.Parser().Parse(text.NewReader(pageMarkdown), parser.WithContext(parser.NewContext()))
+
+	page := SearchIndexPage{Title: matter.Title, Href: urlPath}
+	section := SearchIndexSection{}
+	var sectionText strings.Builder
+	for child := document.FirstChild(); child != nil; child = child.NextSibling() {
+		heading, ok := child.(*ast.Heading)
+		if !ok {
+			writeSearchText(&sectionText, child, pageMarkdown)
+			continue
+		}
+		section.Text = strings.Join(strings.Fields(sectionText.String()), " ")
+		if section.Heading != "" || section.Text != "" {
+			page.Sections = append(page.Sections, section)
+		}
+		sectionText.Reset()
+		section = SearchIndexSection{Heading: string(heading.Text(pageMarkdown))}
+		if id, ok := heading.AttributeString("id"); ok {
+			section.Id = string(id.([]byte))
+		}
+	}
+	section.Text = strings.Join(strings.Fields(sectionText.String()), " ")
+	if section.Heading != "" || section.Text != "" {
+		page.Sections = append(page.Sections, section)
+	}
+	return page, nil
+}
+
+// writeSearchText writes the readable text of the node, skipping code blocks and raw HTML.
+func writeSearchText(w *strings.Builder, node ast.Node, source []byte) {
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			if n.Type() == ast.TypeBlock {
+				w.WriteString(" ")
+			}
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.HTMLBlock, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			w.Write(n.Segment.Value(source))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				w.WriteString(" ")
+			}
+		case *ast.String:
+			w.Write(n.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+}
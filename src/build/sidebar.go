@@ -0,0 +1,167 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pilcrowOnPaper/malta/utils"
+)
+
+func (matter pageMatter) sidebarLabel(fallback string) string {
+	if matter.SidebarLabel != "" {
+		return matter.SidebarLabel
+	}
+	if matter.Title != "" {
+		return matter.Title
+	}
+	return fallback
+}
+
+type sidebarItem struct {
+	name     string
+	position *int
+}
+
+// less orders items with a sidebar_position before items without one,
+// and falls back to the file name.
+func (a sidebarItem) less(b sidebarItem) bool {
+	if a.position != nil && b.position != nil && *a.position != *b.position {
+		return *a.position < *b.position
+	}
+	if (a.position == nil) != (b.position == nil) {
+		return a.position != nil
+	}
+	return a.name < b.name
+}
+
+// generateNavSections creates a section for each directory inside the pages directory.
+// Pages at the root of the pages directory, except the index page, are placed in an untitled first section.
+func generateNavSections() ([]NavSection, error) {
+	dirEntries, err := os.ReadDir("pages")
+	if err != nil {
+		return nil, err
+	}
+	rootSection := NavSection{Pages: []NavPage{}}
+	var rootPageItems []sidebarItem
+	var sections []NavSection
+	var sectionItems []sidebarItem
+	for _, dirEntry := range dirEntries {
+		entryPath := filepath.Join("pages", dirEntry.Name())
+		if dirEntry.IsDir() {
+			section, position, err := generateNavSection(entryPath)
+			if err != nil {
+				return nil, err
+			}
+			// directories without pages, like image directories, are not sections
+			if section.Href == "" && len(section.Pages) == 0 {
+				continue
+			}
+			sections = append(sections, section)
+			sectionItems = append(sectionItems, sidebarItem{dirEntry.Name(), position})
+			continue
+		}
+		if filepath.Ext(dirEntry.Name()) != ".md" || dirEntry.Name() == "index.md" {
+			continue
+		}
+		matter, err := readPageMatter(entryPath)
+		if err != nil {
+			return nil, err
+		}
+		rootSection.Pages = append(rootSection.Pages, NavPage{
+			Title: matter.sidebarLabel(utils.FilenameWithoutExtension(dirEntry.Name())),
+			Href:  GetPageURLPath(entryPath),
+		})
+		rootPageItems = append(rootPageItems, sidebarItem{dirEntry.Name(), matter.SidebarPosition})
+	}
+	sortByItems(rootSection.Pages, rootPageItems)
+	sortByItems(sections, sectionItems)
+	if len(rootSection.Pages) > 0 {
+		sections = append([]NavSection{rootSection}, sections...)
+	}
+	return sections, nil
+}
+
+// generateNavSection creates a section from the markdown files inside dirPath.
+// The directory's index.md, if present, is used as the section landing page,
+// and its sidebar_position is returned as the position of the section.
+func generateNavSection(dirPath string) (NavSection, *int, error) {
+	section := NavSection{Title: filepath.Base(dirPath), Pages: []NavPage{}}
+	var position *int
+	indexFilePath := filepath.Join(dirPath, "index.md")
+	if _, err := os.Stat(indexFilePath); err == nil {
+		matter, err := readPageMatter(indexFilePath)
+		if err != nil {
+			return section, nil, err
+		}
+		section.Title = matter.sidebarLabel(section.Title)
+		section.Href = GetPageURLPath(indexFilePath)
+		position = matter.SidebarPosition
+	}
+
+	pages, err := generateNavPages(dirPath)
+	section.Pages = pages
+	return section, position, err
+}
+
+// generateNavPages lists the markdown files inside dirPath.
+// Subdirectories are listed as nested groups.
+func generateNavPages(dirPath string) ([]NavPage, error) {
+	dirEntries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	pages := []NavPage{}
+	var items []sidebarItem
+	for _, dirEntry := range dirEntries {
+		entryPath := filepath.Join(dirPath, dirEntry.Name())
+		if dirEntry.IsDir() {
+			group, position, err := generateNavSection(entryPath)
+			if err != nil {
+				return nil, err
+			}
+			if group.Href == "" && len(group.Pages) == 0 {
+				continue
+			}
+			pages = append(pages, NavPage{Title: group.Title, Href: group.Href, Pages: group.Pages})
+			items = append(items, sidebarItem{dirEntry.Name(), position})
+			continue
+		}
+		if filepath.Ext(dirEntry.Name()) != ".md" || dirEntry.Name() == "index.md" {
+			continue
+		}
+		matter, err := readPageMatter(entryPath)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, NavPage{
+			Title: matter.sidebarLabel(utils.FilenameWithoutExtension(dirEntry.Name())),
+			Href:  GetPageURLPath(entryPath),
+		})
+		items = append(items, sidebarItem{dirEntry.Name(), matter.SidebarPosition})
+	}
+	sortByItems(pages, items)
+	return pages, nil
+}
+
+func sortByItems[T any](values []T, items []sidebarItem) {
+	sort.Sort(sidebarSorter[T]{values, items})
+}
+
+type sidebarSorter[T any] struct {
+	values []T
+	items  []sidebarItem
+}
+
+func (s sidebarSorter[T]) Len() int {
+	return len(s.values)
+}
+
+func (s sidebarSorter[T]) Less(i, j int) bool {
+	return s.items[i].less(s.items[j])
+}
+
+func (s sidebarSorter[T]) Swap(i, j int) {
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+	s.items[i], s.items[j] = s.items[j], s.items[i]
+}
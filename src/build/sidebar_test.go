@@ -0,0 +1,38 @@
+package build
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGenerateNavSections(t *testing.T) {
+	setupTestProject(t, map[string]string{
+		"pages/index.md":                "---\ntitle: \"Home\"\n---\n",
+		"pages/about.md":                "---\ntitle: \"About\"\n---\n",
+		"pages/images/logo.png":         "",
+		"pages/guides/index.md":         "---\ntitle: \"Guides\"\nsidebar_position: 2\n---\n",
+		"pages/guides/b.md":             "---\ntitle: \"B\"\n---\n",
+		"pages/guides/a.md":             "---\ntitle: \"A\"\nsidebar_label: \"First\"\nsidebar_position: 1\n---\n",
+		"pages/guides/assets/image.png": "",
+		"pages/basics/setup.md":         "---\ntitle: \"Setup\"\n---\n",
+		"pages/basics/advanced/deep.md": "---\ntitle: \"Deep\"\n---\n",
+	})
+	sections, err := generateNavSections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []NavSection{
+		{Pages: []NavPage{{Title: "About", Href: "/about"}}},
+		{Title: "Guides", Href: "/guides", Pages: []NavPage{
+			{Title: "First", Href: "/guides/a"},
+			{Title: "B", Href: "/guides/b"},
+		}},
+		{Title: "basics", Pages: []NavPage{
+			{Title: "advanced", Pages: []NavPage{{Title: "Deep", Href: "/basics/advanced/deep"}}},
+			{Title: "Setup", Href: "/basics/setup"},
+		}},
+	}
+	if !reflect.DeepEqual(sections, expected) {
+		t.Errorf("\n got: %+v\nwant: %+v", sections, expected)
+	}
+}
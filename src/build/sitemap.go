@@ -0,0 +1,79 @@
+package build
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// GenerateSitemap writes the sitemap of all pages, excluding pages with "sitemap: false",
+// and pages with "noindex: true" unless "sitemap: true" is set.
+// Draft and scheduled pages are always excluded, including in builds with drafts.
+// lastModified is either "mtime", "git", or empty to omit the last modified date.
+func GenerateSitemap(domain string, markdownFilePaths []string, lastModified string, dst io.Writer) error {
+	now := time.Now()
+	urlSet := sitemapURLSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, markdownFilePath := range markdownFilePaths {
+		matter, err := readPageMatter(markdownFilePath)
+		if err != nil {
+			return err
+		}
+		if matter.unpublished(now) {
+			continue
+		}
+		if matter.Sitemap != nil && !*matter.Sitemap || matter.Sitemap == nil && matter.Noindex {
+			continue
+		}
+		url := sitemapURL{Loc: strings.TrimSuffix(domain, "/") + GetPageURLPath(markdownFilePath)}
+		if lastModified != "" {
+			modTime, err := getLastModified(markdownFilePath, lastModified)
+			if err != nil {
+				return err
+			}
+			url.LastMod = modTime.Format("2006-01-02")
+		}
+		urlSet.URLs = append(urlSet.URLs, url)
+	}
+	if _, err := io.WriteString(dst, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(dst)
+	encoder.Indent("", "  ")
+	return encoder.Encode(urlSet)
+}
+
+// getLastModified uses the date of the last commit that modified the file if lastModified is "git",
+// and falls back to the file modification time for files that were not committed.
+func getLastModified(filePath string, lastModified string) (time.Time, error) {
+	if lastModified == "git" {
+		output, err := exec.Command("git", "log", "-1", "--format=%cI", "--", filePath).Output()
+		if err == nil && len(strings.TrimSpace(string(output))) > 0 {
+			return time.Parse(time.RFC3339, strings.TrimSpace(string(output)))
+		}
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+func GenerateRobotsTxt(domain string, dst io.Writer) error {
+	_, err := fmt.Fprintf(dst, "User-agent: *\nAllow: /\n\nSitemap: %s/sitemap.xml\n", strings.TrimSuffix(domain, "/"))
+	return err
+}
@@ -0,0 +1,43 @@
+package build
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSitemap(t *testing.T) {
+	setupTestProject(t, map[string]string{
+		"pages/index.md":     "---\ntitle: \"Home\"\n---\n",
+		"pages/draft.md":     "---\ntitle: \"Draft\"\ndraft: true\nsitemap: true\n---\n",
+		"pages/scheduled.md": "---\ntitle: \"Scheduled\"\npublish_date: 2999-01-01\n---\n",
+		"pages/published.md": "---\ntitle: \"Published\"\npublish_date: 2000-01-01\n---\n",
+		"pages/hidden.md":    "---\ntitle: \"Hidden\"\nnoindex: true\n---\n",
+		"pages/listed.md":    "---\ntitle: \"Listed\"\nnoindex: true\nsitemap: true\n---\n",
+		"pages/excluded.md":  "---\ntitle: \"Excluded\"\nsitemap: false\n---\n",
+	})
+	// the pages of a build with drafts
+	markdownFilePaths := []string{
+		"pages/index.md",
+		"pages/draft.md",
+		"pages/scheduled.md",
+		"pages/published.md",
+		"pages/hidden.md",
+		"pages/listed.md",
+		"pages/excluded.md",
+	}
+	var sitemap bytes.Buffer
+	if err := GenerateSitemap("https://example.com", markdownFilePaths, "", &sitemap); err != nil {
+		t.Fatal(err)
+	}
+	for _, urlPath := range []string{"/", "/published", "/listed"} {
+		if !strings.Contains(sitemap.String(), "<loc>https://example.com"+urlPath+"</loc>") {
+			t.Errorf("expected %s in the sitemap", urlPath)
+		}
+	}
+	for _, urlPath := range []string{"/draft", "/scheduled", "/hidden", "/excluded"} {
+		if strings.Contains(sitemap.String(), "<loc>https://example.com"+urlPath+"</loc>") {
+			t.Errorf("unexpected %s in the sitemap", urlPath)
+		}
+	}
+}
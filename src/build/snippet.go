@@ -0,0 +1,203 @@
+package build
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+)
+
+// code block attributes set by codeBlockLinksAstTransformer when a code block includes a snippet
+const (
+	codeBlockContentAttribute  = "content"
+	codeBlockLanguageAttribute = "language"
+)
+
+// parseSnippetDirective returns the target of a "<<< path" line.
+func parseSnippetDirective(line string) (string, bool) {
+	target, ok := strings.CutPrefix(strings.TrimSpace(line), "<<<")
+	if !ok {
+		return "", false
+	}
+	return strings.TrimSpace(target), true
+}
+
+// resolveSnippet reads the snippet referenced by the target of a snippet directive.
+// Paths starting with "@/" are relative to the project root and other paths are relative to the markdown file.
+// The path can be followed by "#name" to only include a region, or by "#L10-L30" to only include some lines.
+// language is inferred from the file extension.
+func resolveSnippet(markdownFilePath string, target string) (content string, language string, err error) {
+	snippetPath, fragment, _ := strings.Cut(target, "#")
+	if snippetPath == "" {
+		return "", "", errors.New("missing snippet path")
+	}
+	filePath := filepath.FromSlash(snippetPath)
+	if projectPath, ok := strings.CutPrefix(snippetPath, "@/"); ok {
+		filePath = filepath.FromSlash(projectPath)
+	} else {
+		filePath = filepath.Join(filepath.Dir(markdownFilePath), filePath)
+	}
+	if !isProjectFilePath(filePath) {
+		return "", "", fmt.Errorf("snippet must be inside the project: %s", snippetPath)
+	}
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", "", fmt.Errorf("missing snippet file: %s", snippetPath)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if fragment != "" {
+		lines, err = selectSnippetLines(lines, fragment)
+		if err != nil {
+			return "", "", fmt.Errorf("%v in %s", err, snippetPath)
+		}
+	}
+	lines = removeRegionMarkers(lines)
+	content = strings.Join(dedentLines(lines), "")
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content, strings.TrimPrefix(filepath.Ext(filePath), "."), nil
+}
+
+var lineRangeFragmentRegex = regexp.MustCompile(`^L(\d+)(?:-L?(\d+))?$`)
+
+var regionStartRegex = regexp.MustCompile(`#region\s+([\w-]+)`)
+var regionEndRegex = regexp.MustCompile(`#endregion\b`)
+
+// selectSnippetLines returns the lines of the region or the line range defined by fragment.
+func selectSnippetLines(lines []string, fragment string) ([]string, error) {
+	if match := lineRangeFragmentRegex.FindStringSubmatch(fragment); match != nil {
+		start, _ := strconv.Atoi(match[1])
+		end := start
+		if match[2] != "" {
+			end, _ = strconv.Atoi(match[2])
+		}
+		if start < 1 || end < start || end > len(lines) {
+			return nil, fmt.Errorf("invalid line range: %s", fragment)
+		}
+		return lines[start-1 : end], nil
+	}
+	start := -1
+	depth := 0
+	for i, line := range lines {
+		if match := regionStartRegex.FindStringSubmatch(line); match != nil {
+			if start < 0 && match[1] == fragment {
+				start = i + 1
+				continue
+			}
+			depth++
+			continue
+		}
+		if start < 0 || !regionEndRegex.MatchString(line) {
+			continue
+		}
+		if depth == 0 {
+			return lines[start:i], nil
+		}
+		depth--
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("missing region: %s", fragment)
+	}
+	return nil, fmt.Errorf("missing end of region: %s", fragment)
+}
+
+func removeRegionMarkers(lines []string) []string {
+	var result []string
+	for _, line := range lines {
+		if regionStartRegex.MatchString(line) || regionEndRegex.MatchString(line) {
+			continue
+		}
+		result = append(result, line)
+	}
+	return result
+}
+
+// dedentLines removes the indentation shared by every non-blank line.
+func dedentLines(lines []string) []string {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lineIndent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || lineIndent < indent {
+			indent = lineIndent
+		}
+	}
+	if indent <= 0 {
+		return lines
+	}
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			result[i] = strings.TrimLeft(line, " \t")
+			continue
+		}
+		result[i] = line[indent:]
+	}
+	return result
+}
+
+// resolveCodeBlockSnippets replaces the snippet directives of the code block with the content of the snippets.
+// The resolved content is stored as an attribute since it is not part of the markdown source.
+func resolveCodeBlockSnippets(codeBlock *ast.FencedCodeBlock, source []byte, markdownFilePath string, pc parser.Context) {
+	var content strings.Builder
+	var language string
+	replaced := false
+	for i := 0; i < codeBlock.Lines().Len(); i++ {
+		line := codeBlock.Lines().At(i)
+		value := line.Value(source)
+		// "\<<<" is rendered as "<<<" without including a snippet
+		if bytes.HasPrefix(bytes.TrimLeft(value, " \t"), []byte("\\<<<")) {
+			content.Write(bytes.Replace(value, []byte("\\<<<"), []byte("<<<"), 1))
+			replaced = true
+			continue
+		}
+		target, ok := parseSnippetDirective(string(value))
+		if !ok {
+			content.Write(value)
+			continue
+		}
+		replaced = true
+		snippet, snippetLanguage, err := resolveSnippet(markdownFilePath, target)
+		if err != nil {
+			addPageIssue(pc, bytes.Count(source[:line.Start], []byte("\n"))+1, err.Error())
+			continue
+		}
+		content.WriteString(snippet)
+		if language == "" {
+			language = snippetLanguage
+		}
+	}
+	if !replaced {
+		return
+	}
+	codeBlock.SetAttributeString(codeBlockContentAttribute, content.String())
+	if len(codeBlock.Language(source)) == 0 && language != "" {
+		codeBlock.SetAttributeString(codeBlockLanguageAttribute, language)
+	}
+}
+
+// codeBlockLanguage returns the language of the code block,
+// which is inferred from the included snippets when not defined.
+func codeBlockLanguage(codeBlock *ast.FencedCodeBlock, source []byte) string {
+	if language, ok := codeBlock.AttributeString(codeBlockLanguageAttribute); ok {
+		return language.(string)
+	}
+	return string(codeBlock.Language(source))
+}
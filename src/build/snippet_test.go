@@ -0,0 +1,48 @@
+package build
+
+import (
+	"testing"
+)
+
+func TestResolveSnippet(t *testing.T) {
+	setupTestProject(t, map[string]string{
+		"src/main.go":   "package main\n\nfunc main() {\n\t// #region print\n\tprintln(\"hello\")\n\t// #endregion\n}\n",
+		"pages/code.ts": "const a = 1;\n",
+	})
+	tests := []struct {
+		target   string
+		content  string
+		language string
+	}{
+		{"./code.ts", "const a = 1;\n", "ts"},
+		{"@/src/main.go#L1", "package main\n", "go"},
+		{"@/src/main.go#L3-L7", "func main() {\n\tprintln(\"hello\")\n}\n", "go"},
+		{"@/src/main.go#print", "println(\"hello\")\n", "go"},
+	}
+	for _, test := range tests {
+		content, language, err := resolveSnippet("pages/index.md", test.target)
+		if err != nil {
+			t.Errorf("%s: %v", test.target, err)
+			continue
+		}
+		if content != test.content || language != test.language {
+			t.Errorf("%s: expected %q (%s), got %q (%s)", test.target, test.content, test.language, content, language)
+		}
+	}
+
+	errorTests := []struct {
+		target   string
+		expected string
+	}{
+		{"./missing.ts", "missing snippet file: ./missing.ts"},
+		{"../../outside.go", "snippet must be inside the project: ../../outside.go"},
+		{"@/src/main.go#L8-L20", "invalid line range: L8-L20 in @/src/main.go"},
+		{"@/src/main.go#missing", "missing region: missing in @/src/main.go"},
+	}
+	for _, test := range errorTests {
+		_, _, err := resolveSnippet("pages/index.md", test.target)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("%s: expected %q, got %v", test.target, test.expected, err)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package build
+
+import "html"
+
+// texSymbol is a command rendered as a single character.
+type texSymbol struct {
+	text string
+	// identifiers are rendered with mi and operators with mo
+	operator bool
+	// upright identifiers, like uppercase Greek letters
+	upright bool
+	limits  bool
+}
+
+func (s texSymbol) mathml() string {
+	text := html.EscapeString(s.text)
+	if s.operator {
+		return "<mo>" + text + "</mo>"
+	}
+	if s.upright {
+		return `<mi mathvariant="normal">` + text + "</mi>"
+	}
+	return "<mi>" + text + "</mi>"
+}
+
+func texIdentifiers(symbols map[string]string) map[string]texSymbol {
+	result := make(map[string]texSymbol, len(symbols))
+	for name, text := range symbols {
+		result[name] = texSymbol{text: text}
+	}
+	return result
+}
+
+func texOperators(symbols map[string]string) map[string]texSymbol {
+	result := make(map[string]texSymbol, len(symbols))
+	for name, text := range symbols {
+		result[name] = texSymbol{text: text, operator: true}
+	}
+	return result
+}
+
+var texSymbols = func() map[string]texSymbol {
+	symbols := map[string]texSymbol{}
+	add := func(group map[string]texSymbol) {
+		for name, symbol := range group {
+			symbols[name] = symbol
+		}
+	}
+	add(texIdentifiers(map[string]string{
+		"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ", "varepsilon": "ε",
+		"zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ",
+		"lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "omicron": "ο", "pi": "π", "varpi": "ϖ",
+		"rho": "ρ", "varrho": "ϱ", "sigma": "σ", "varsigma": "ς", "tau": "τ", "upsilon": "υ",
+		"phi": "ϕ", "varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+		"infty": "∞", "partial": "∂", "nabla": "∇", "ell": "ℓ", "hbar": "ℏ", "aleph": "ℵ",
+		"emptyset": "∅", "varnothing": "∅", "Re": "ℜ", "Im": "ℑ", "wp": "℘", "imath": "ı", "jmath": "ȷ",
+		"top": "⊤", "bot": "⊥", "angle": "∠", "triangle": "△", "checkmark": "✓",
+	}))
+	for name, text := range map[string]string{
+		"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+		"Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+	} {
+		symbols[name] = texSymbol{text: text, upright: true}
+	}
+	add(texOperators(map[string]string{
+		// binary operators
+		"pm": "±", "mp": "∓", "times": "×", "div": "÷", "cdot": "⋅", "ast": "∗", "star": "⋆",
+		"circ": "∘", "bullet": "∙", "oplus": "⊕", "ominus": "⊖", "otimes": "⊗", "oslash": "⊘",
+		"odot": "⊙", "cup": "∪", "cap": "∩", "setminus": "∖", "wedge": "∧", "land": "∧",
+		"vee": "∨", "lor": "∨", "neg": "¬", "lnot": "¬", "sqcup": "⊔", "sqcap": "⊓",
+		"uplus": "⊎", "dagger": "†", "ddagger": "‡", "wr": "≀", "amalg": "⨿",
+		// relations
+		"leq": "≤", "le": "≤", "geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠", "equiv": "≡",
+		"approx": "≈", "cong": "≅", "sim": "∼", "simeq": "≃", "propto": "∝", "ll": "≪",
+		"gg": "≫", "in": "∈", "notin": "∉", "ni": "∋", "subset": "⊂", "supset": "⊃",
+		"subseteq": "⊆", "supseteq": "⊇", "nsubseteq": "⊈", "perp": "⊥", "parallel": "∥",
+		"mid": "∣", "nmid": "∤", "vdash": "⊢", "dashv": "⊣", "models": "⊨", "prec": "≺",
+		"succ": "≻", "preceq": "⪯", "succeq": "⪰", "doteq": "≐", "coloneqq": "≔",
+		"triangleq": "≜", "asymp": "≍", "lt": "<", "gt": ">",
+		// arrows
+		"to": "→", "rightarrow": "→", "leftarrow": "←", "gets": "←", "leftrightarrow": "↔",
+		"Rightarrow": "⇒", "Leftarrow": "⇐", "Leftrightarrow": "⇔", "implies": "⟹",
+		"impliedby": "⟸", "iff": "⟺", "mapsto": "↦", "longrightarrow": "⟶",
+		"longleftarrow": "⟵", "longleftrightarrow": "⟷", "longmapsto": "⟼",
+		"Longrightarrow": "⟹", "Longleftarrow": "⟸", "Longleftrightarrow": "⟺",
+		"uparrow": "↑", "downarrow": "↓", "updownarrow": "↕", "Uparrow": "⇑", "Downarrow": "⇓",
+		"hookrightarrow": "↪", "hookleftarrow": "↩", "rightharpoonup": "⇀", "leftharpoonup": "↼",
+		"rightleftharpoons": "⇌", "nearrow": "↗", "searrow": "↘", "swarrow": "↙", "nwarrow": "↖",
+		// punctuation and others
+		"ldots": "…", "dots": "…", "cdots": "⋯", "vdots": "⋮", "ddots": "⋱", "colon": ":",
+		"forall": "∀", "exists": "∃", "nexists": "∄", "therefore": "∴", "because": "∵",
+		"langle": "⟨", "rangle": "⟩", "lvert": "|", "rvert": "|", "vert": "|", "lVert": "‖",
+		"rVert": "‖", "Vert": "‖", "lfloor": "⌊", "rfloor": "⌋", "lceil": "⌈", "rceil": "⌉",
+		"backslash": "\\", "lbrace": "{", "rbrace": "}", "prime": "′",
+		"{": "{", "}": "}", "|": "‖", "$": "$", "%": "%", "#": "#", "&": "&", "_": "_",
+		// integrals
+		"int": "∫", "iint": "∬", "iiint": "∭", "oint": "∮",
+	}))
+	for name, text := range map[string]string{
+		"sum": "∑", "prod": "∏", "coprod": "∐", "bigcup": "⋃", "bigcap": "⋂",
+		"bigoplus": "⨁", "bigotimes": "⨂", "bigodot": "⨀", "biguplus": "⨄",
+		"bigvee": "⋁", "bigwedge": "⋀", "bigsqcup": "⨆",
+	} {
+		symbols[name] = texSymbol{text: text, operator: true, limits: true}
+	}
+	return symbols
+}()
+
+// texFunction is a function name like \sin.
+// Functions with limits have their subscript placed below in display mode, like \lim.
+type texFunction struct {
+	text   string
+	limits bool
+}
+
+var texFunctions = map[string]texFunction{
+	"arccos": {"arccos", false}, "arcsin": {"arcsin", false}, "arctan": {"arctan", false},
+	"arg": {"arg", false}, "cos": {"cos", false}, "cosh": {"cosh", false}, "cot": {"cot", false},
+	"coth": {"coth", false}, "csc": {"csc", false}, "deg": {"deg", false}, "dim": {"dim", false},
+	"exp": {"exp", false}, "hom": {"hom", false}, "ker": {"ker", false}, "lg": {"lg", false},
+	"ln": {"ln", false}, "log": {"log", false}, "sec": {"sec", false}, "sin": {"sin", false},
+	"sinh": {"sinh", false}, "tan": {"tan", false}, "tanh": {"tanh", false},
+	"det": {"det", true}, "gcd": {"gcd", true}, "inf": {"inf", true}, "lim": {"lim", true},
+	"liminf": {"lim inf", true}, "limsup": {"lim sup", true}, "max": {"max", true},
+	"min": {"min", true}, "Pr": {"Pr", true}, "sup": {"sup", true},
+}
+
+var texSpaces = map[string]string{
+	",":          "0.1667em",
+	"thinspace":  "0.1667em",
+	":":          "0.2222em",
+	">":          "0.2222em",
+	"medspace":   "0.2222em",
+	";":          "0.2778em",
+	"thickspace": "0.2778em",
+	"!":          "-0.1667em",
+	" ":          "0.25em",
+	"quad":       "1em",
+	"qquad":      "2em",
+}
+
+// texAccent is a mark placed above or below its argument.
+type texAccent struct {
+	text     string
+	under    bool
+	stretchy bool
+	// scripts are placed above or below the accent, like with \underbrace
+	limits bool
+}
+
+var texAccents = map[string]texAccent{
+	"hat":            {text: "^"},
+	"widehat":        {text: "^", stretchy: true},
+	"check":          {text: "ˇ"},
+	"bar":            {text: "¯"},
+	"overline":       {text: "‾", stretchy: true},
+	"vec":            {text: "→"},
+	"overrightarrow": {text: "→", stretchy: true},
+	"overleftarrow":  {text: "←", stretchy: true},
+	"tilde":          {text: "~"},
+	"widetilde":      {text: "~", stretchy: true},
+	"dot":            {text: "˙"},
+	"ddot":           {text: "¨"},
+	"acute":          {text: "´"},
+	"grave":          {text: "`"},
+	"breve":          {text: "˘"},
+	"overbrace":      {text: "⏞", stretchy: true, limits: true},
+	"underline":      {text: "_", under: true, stretchy: true},
+	"underbrace":     {text: "⏟", under: true, stretchy: true, limits: true},
+}
+
+var texDelimiters = map[string]string{
+	"{": "{", "}": "}", "lbrace": "{", "rbrace": "}", "langle": "⟨", "rangle": "⟩",
+	"lvert": "|", "rvert": "|", "vert": "|", "|": "‖", "lVert": "‖", "rVert": "‖", "Vert": "‖",
+	"lfloor": "⌊", "rfloor": "⌋", "lceil": "⌈", "rceil": "⌉", "backslash": "\\",
+	"uparrow": "↑", "downarrow": "↓", "Uparrow": "⇑", "Downarrow": "⇓",
+}
+
+var texDelimiterSizes = map[string]string{
+	"big":  "1.2em",
+	"Big":  "1.8em",
+	"bigg": "2.4em",
+	"Bigg": "3em",
+}
+
+// negated relations that have their own character
+var texNegations = map[string]string{
+	"=": "≠", "<": "≮", ">": "≯", "∈": "∉", "≡": "≢", "⊂": "⊄", "⊃": "⊅", "⊆": "⊈",
+	"⊇": "⊉", "∼": "≁", "≈": "≉", "≤": "≰", "≥": "≱", "∣": "∤", "∃": "∄",
+}
+
+// texFont maps letters and digits to the Mathematical Alphanumeric Symbols block,
+// since MathML Core doesn't support mathvariant values other than "normal".
+type texFont struct {
+	upper rune
+	lower rune
+	digit rune
+	// characters outside the block, which has holes for letters already defined elsewhere
+	exceptions map[rune]rune
+	// upright letters, with mathvariant="normal"
+	normal bool
+}
+
+func (f *texFont) apply(r rune) rune {
+	if mapped, ok := f.exceptions[r]; ok {
+		return mapped
+	}
+	switch {
+	case r >= 'A' && r <= 'Z' && f.upper != 0:
+		return f.upper + r - 'A'
+	case r >= 'a' && r <= 'z' && f.lower != 0:
+		return f.lower + r - 'a'
+	case r >= '0' && r <= '9' && f.digit != 0:
+		return f.digit + r - '0'
+	}
+	return r
+}
+
+var texFonts = map[string]*texFont{
+	"mathrm":     {normal: true},
+	"mathit":     {},
+	"mathbf":     {upper: 0x1D400, lower: 0x1D41A, digit: 0x1D7CE},
+	"boldsymbol": {upper: 0x1D468, lower: 0x1D482, digit: 0x1D7CE},
+	"bm":         {upper: 0x1D468, lower: 0x1D482, digit: 0x1D7CE},
+	"mathbb": {upper: 0x1D538, lower: 0x1D552, digit: 0x1D7D8, exceptions: map[rune]rune{
+		'C': 'ℂ', 'H': 'ℍ', 'N': 'ℕ', 'P': 'ℙ', 'Q': 'ℚ', 'R': 'ℝ', 'Z': 'ℤ',
+	}},
+	"mathcal": {upper: 0x1D49C, lower: 0x1D4B6, exceptions: map[rune]rune{
+		'B': 'ℬ', 'E': 'ℰ', 'F': 'ℱ', 'H': 'ℋ', 'I': 'ℐ', 'L': 'ℒ', 'M': 'ℳ', 'R': 'ℛ',
+		'e': 'ℯ', 'g': 'ℊ', 'o': 'ℴ',
+	}},
+	"mathfrak": {upper: 0x1D504, lower: 0x1D51E, exceptions: map[rune]rune{
+		'C': 'ℭ', 'H': 'ℌ', 'I': 'ℑ', 'R': 'ℜ', 'Z': 'ℨ',
+	}},
+	"mathsf": {upper: 0x1D5A0, lower: 0x1D5BA, digit: 0x1D7E2},
+	"mathtt": {upper: 0x1D670, lower: 0x1D68A, digit: 0x1D7F6},
+}
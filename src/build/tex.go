@@ -0,0 +1,724 @@
+package build
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TexError is a syntax error in a TeX expression.
+// Offset is the byte offset of the error inside the expression.
+type TexError struct {
+	Offset  int
+	Message string
+}
+
+func (e *TexError) Error() string {
+	return e.Message
+}
+
+// texToMathML converts a TeX math expression to a MathML element.
+// Only the commands commonly used in documentation are supported.
+func texToMathML(tex string, display bool) (string, error) {
+	p := &texParser{src: tex}
+	rows, err := p.parseRows(func(t texToken) bool { return false })
+	if err != nil {
+		return "", err
+	}
+	var body string
+	if len(rows) == 1 {
+		body = rows[0]
+	} else {
+		body = texTable(rows, nil)
+	}
+	var b strings.Builder
+	b.WriteString(`<math xmlns="http://www.w3.org/1998/Math/MathML"`)
+	if display {
+		b.WriteString(` display="block"`)
+	}
+	b.WriteString("><semantics>")
+	b.WriteString(texGroup(body))
+	b.WriteString(`<annotation encoding="application/x-tex">`)
+	b.WriteString(html.EscapeString(strings.TrimSpace(tex)))
+	b.WriteString("</annotation></semantics></math>")
+	return b.String(), nil
+}
+
+type texTokenKind int
+
+const (
+	texTokenEOF texTokenKind = iota
+	texTokenCommand
+	texTokenChar
+)
+
+// texToken is either a command, where value is the name without the backslash,
+// or a single character.
+type texToken struct {
+	kind   texTokenKind
+	value  string
+	offset int
+}
+
+func (t texToken) isChar(value string) bool {
+	return t.kind == texTokenChar && t.value == value
+}
+
+func (t texToken) isCommand(value string) bool {
+	return t.kind == texTokenCommand && t.value == value
+}
+
+func (t texToken) String() string {
+	if t.kind == texTokenCommand {
+		return "\\" + t.value
+	}
+	return t.value
+}
+
+type texParser struct {
+	src  string
+	pos  int
+	font *texFont
+}
+
+func (p *texParser) errorf(offset int, format string, args ...interface{}) error {
+	return &TexError{offset, fmt.Sprintf(format, args...)}
+}
+
+func (p *texParser) skipSpaces() {
+	for p.pos < len(p.src) && strings.ContainsRune(" \t\r\n", rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *texParser) next() texToken {
+	p.skipSpaces()
+	if p.pos >= len(p.src) {
+		return texToken{texTokenEOF, "", p.pos}
+	}
+	start := p.pos
+	if p.src[p.pos] == '\\' {
+		p.pos++
+		if p.pos >= len(p.src) {
+			return texToken{texTokenCommand, "", start}
+		}
+		nameStart := p.pos
+		for p.pos < len(p.src) && isASCIILetter(p.src[p.pos]) {
+			p.pos++
+		}
+		if p.pos == nameStart {
+			_, size := utf8.DecodeRuneInString(p.src[p.pos:])
+			p.pos += size
+		}
+		return texToken{texTokenCommand, p.src[nameStart:p.pos], start}
+	}
+	_, size := utf8.DecodeRuneInString(p.src[p.pos:])
+	p.pos += size
+	return texToken{texTokenChar, p.src[start:p.pos], start}
+}
+
+func (p *texParser) peek() texToken {
+	pos := p.pos
+	token := p.next()
+	p.pos = pos
+	return token
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isASCIIDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// texAtom is a parsed element.
+// limits is true if scripts are placed above and below the element instead of on its side.
+type texAtom struct {
+	mathml   string
+	limits   bool
+	function bool
+}
+
+// parseRows parses rows separated by "\\" until the end of the input or a token matched by stop.
+func (p *texParser) parseRows(stop func(texToken) bool) ([]string, error) {
+	var rows []string
+	for {
+		row, err := p.parseRow(func(t texToken) bool { return t.isCommand("\\") || stop(t) })
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+		if !p.peek().isCommand("\\") {
+			return rows, nil
+		}
+		p.next()
+	}
+}
+
+// parseRow parses elements until the end of the input or a token matched by stop.
+// The stop token is not consumed.
+func (p *texParser) parseRow(stop func(texToken) bool) (string, error) {
+	var elements []string
+	for {
+		token := p.peek()
+		if token.kind == texTokenEOF || stop(token) {
+			break
+		}
+		if token.isCommand("displaystyle") || token.isCommand("textstyle") {
+			p.next()
+			rest, err := p.parseRow(stop)
+			if err != nil {
+				return "", err
+			}
+			elements = append(elements, fmt.Sprintf(`<mstyle displaystyle="%t" scriptlevel="0">%s</mstyle>`, token.value == "displaystyle", rest))
+			break
+		}
+		element, err := p.parseScripts()
+		if err != nil {
+			return "", err
+		}
+		elements = append(elements, element)
+	}
+	return texRow(elements), nil
+}
+
+// parseScripts parses an element followed by its subscript and superscript.
+func (p *texParser) parseScripts() (string, error) {
+	base, err := p.parseAtom(false)
+	if err != nil {
+		return "", err
+	}
+	var sub, sup string
+	var hasSub, hasSup bool
+	// primes are part of the superscript
+	var primes []string
+	for {
+		token := p.peek()
+		if token.isChar("^") || token.isChar("_") {
+			p.next()
+			if token.value == "^" && hasSup {
+				return "", p.errorf(token.offset, "double superscript")
+			}
+			if token.value == "_" && hasSub {
+				return "", p.errorf(token.offset, "double subscript")
+			}
+			script, err := p.parseArgument(token)
+			if err != nil {
+				return "", err
+			}
+			if token.value == "^" {
+				sup, hasSup = script, true
+			} else {
+				sub, hasSub = script, true
+			}
+		} else if token.isChar("'") && !hasSup {
+			p.next()
+			primes = append(primes, "<mo>′</mo>")
+		} else if token.isCommand("limits") || token.isCommand("nolimits") {
+			p.next()
+			base.limits = token.value == "limits"
+		} else {
+			break
+		}
+	}
+	if len(primes) > 0 {
+		if hasSup {
+			primes = append(primes, sup)
+		}
+		sup, hasSup = texRow(primes), true
+	}
+	element := base.mathml
+	switch {
+	case hasSub && hasSup && base.limits:
+		element = fmt.Sprintf("<munderover>%s%s%s</munderover>", element, sub, sup)
+	case hasSub && hasSup:
+		element = fmt.Sprintf("<msubsup>%s%s%s</msubsup>", element, sub, sup)
+	case hasSub && base.limits:
+		element = fmt.Sprintf("<munder>%s%s</munder>", element, sub)
+	case hasSub:
+		element = fmt.Sprintf("<msub>%s%s</msub>", element, sub)
+	case hasSup && base.limits:
+		element = fmt.Sprintf("<mover>%s%s</mover>", element, sup)
+	case hasSup:
+		element = fmt.Sprintf("<msup>%s%s</msup>", element, sup)
+	}
+	if base.function {
+		// U+2061 FUNCTION APPLICATION
+		element = "<mrow>" + element + "<mo>\u2061</mo></mrow>"
+	}
+	return element, nil
+}
+
+// parseArgument parses the argument of a command or a script,
+// which is either a group or a single element.
+func (p *texParser) parseArgument(command texToken) (string, error) {
+	token := p.peek()
+	if token.kind == texTokenEOF || token.isChar("}") || token.isChar("&") || token.isChar("^") || token.isChar("_") || token.isCommand("\\") {
+		return "", p.errorf(command.offset, "missing argument for %s", command)
+	}
+	atom, err := p.parseAtom(true)
+	if err != nil {
+		return "", err
+	}
+	return atom.mathml, nil
+}
+
+// parseGroup parses the content of a group after its opening brace.
+func (p *texParser) parseGroup(open texToken) (string, error) {
+	row, err := p.parseRow(func(t texToken) bool { return t.isChar("}") })
+	if err != nil {
+		return "", err
+	}
+	if !p.next().isChar("}") {
+		return "", p.errorf(open.offset, "missing closing brace")
+	}
+	return row, nil
+}
+
+// readRawGroup returns the content of a group as written.
+func (p *texParser) readRawGroup(command texToken) (string, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.src) || p.src[p.pos] != '{' {
+		return "", p.errorf(command.offset, "missing argument for %s", command)
+	}
+	start := p.pos
+	depth := 0
+	for ; p.pos < len(p.src); p.pos++ {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return p.src[start+1 : p.pos-1], nil
+			}
+		}
+	}
+	return "", p.errorf(start, "missing closing brace")
+}
+
+// parseAtom parses a single element without its scripts.
+// When argument is true, numbers are limited to a single digit like in TeX.
+func (p *texParser) parseAtom(argument bool) (texAtom, error) {
+	token := p.next()
+	switch token.kind {
+	case texTokenEOF:
+		return texAtom{}, p.errorf(token.offset, "unexpected end of expression")
+	case texTokenCommand:
+		return p.parseCommand(token)
+	}
+	switch {
+	case token.value == "{":
+		row, err := p.parseGroup(token)
+		return texAtom{mathml: texGroup(row)}, err
+	case token.value == "}":
+		return texAtom{}, p.errorf(token.offset, "unexpected closing brace")
+	case token.value == "&":
+		return texAtom{}, p.errorf(token.offset, "unexpected &")
+	case token.value == "^" || token.value == "_":
+		return texAtom{}, p.errorf(token.offset, "missing base for %s", token.value)
+	case isASCIIDigit(token.value[0]):
+		number := token.value
+		for !argument && p.pos < len(p.src) {
+			c := p.src[p.pos]
+			if !isASCIIDigit(c) && !(c == '.' && p.pos+1 < len(p.src) && isASCIIDigit(p.src[p.pos+1])) {
+				break
+			}
+			number += string(c)
+			p.pos++
+		}
+		return texAtom{mathml: "<mn>" + p.applyFont(number) + "</mn>"}, nil
+	case token.value == "~":
+		return texAtom{mathml: "<mtext> </mtext>"}, nil
+	case token.value == "'":
+		return texAtom{mathml: "<mo>′</mo>"}, nil
+	}
+	r, _ := utf8.DecodeRuneInString(token.value)
+	if unicode.IsLetter(r) {
+		if p.font != nil && p.font.normal {
+			return texAtom{mathml: `<mi mathvariant="normal">` + html.EscapeString(token.value) + "</mi>"}, nil
+		}
+		return texAtom{mathml: "<mi>" + html.EscapeString(p.applyFont(token.value)) + "</mi>"}, nil
+	}
+	return texAtom{mathml: "<mo>" + html.EscapeString(texCharOperators(token.value)) + "</mo>"}, nil
+}
+
+func texCharOperators(value string) string {
+	switch value {
+	case "-":
+		return "−"
+	case "*":
+		return "∗"
+	}
+	return value
+}
+
+func (p *texParser) parseCommand(token texToken) (texAtom, error) {
+	name := token.value
+	if symbol, ok := texSymbols[name]; ok {
+		return texAtom{mathml: symbol.mathml(), limits: symbol.limits}, nil
+	}
+	if function, ok := texFunctions[name]; ok {
+		if function.limits {
+			return texAtom{mathml: `<mo movablelimits="true" form="prefix">` + function.text + "</mo>", limits: true}, nil
+		}
+		return texAtom{mathml: "<mi>" + function.text + "</mi>", function: true}, nil
+	}
+	if width, ok := texSpaces[name]; ok {
+		return texAtom{mathml: fmt.Sprintf(`<mspace width="%s"/>`, width)}, nil
+	}
+	if font, ok := texFonts[name]; ok {
+		previousFont := p.font
+		p.font = font
+		argument, err := p.parseArgument(token)
+		p.font = previousFont
+		return texAtom{mathml: argument}, err
+	}
+	if accent, ok := texAccents[name]; ok {
+		argument, err := p.parseArgument(token)
+		if err != nil {
+			return texAtom{}, err
+		}
+		if accent.under {
+			return texAtom{mathml: fmt.Sprintf(`<munder accentunder="true">%s<mo stretchy="%t">%s</mo></munder>`, argument, accent.stretchy, accent.text), limits: accent.limits}, nil
+		}
+		return texAtom{mathml: fmt.Sprintf(`<mover accent="true">%s<mo stretchy="%t">%s</mo></mover>`, argument, accent.stretchy, accent.text), limits: accent.limits}, nil
+	}
+	// \big, \bigl, \bigr, \bigm, and their larger variants
+	if size, ok := texDelimiterSizes[strings.TrimRight(name, "lrm")]; ok {
+		delimiter, err := p.parseDelimiter(token)
+		if err != nil {
+			return texAtom{}, err
+		}
+		return texAtom{mathml: fmt.Sprintf(`<mo minsize="%s" maxsize="%s">%s</mo>`, size, size, delimiter)}, nil
+	}
+	switch name {
+	case "frac", "dfrac", "tfrac", "cfrac", "binom":
+		numerator, err := p.parseArgument(token)
+		if err != nil {
+			return texAtom{}, err
+		}
+		denominator, err := p.parseArgument(token)
+		if err != nil {
+			return texAtom{}, err
+		}
+		switch name {
+		case "dfrac", "cfrac":
+			return texAtom{mathml: fmt.Sprintf(`<mstyle displaystyle="true" scriptlevel="0"><mfrac>%s%s</mfrac></mstyle>`, numerator, denominator)}, nil
+		case "tfrac":
+			return texAtom{mathml: fmt.Sprintf(`<mstyle displaystyle="false"><mfrac>%s%s</mfrac></mstyle>`, numerator, denominator)}, nil
+		case "binom":
+			return texAtom{mathml: fmt.Sprintf(`<mrow><mo>(</mo><mfrac linethickness="0">%s%s</mfrac><mo>)</mo></mrow>`, numerator, denominator)}, nil
+		}
+		return texAtom{mathml: fmt.Sprintf("<mfrac>%s%s</mfrac>", numerator, denominator)}, nil
+	case "sqrt":
+		if p.peek().isChar("[") {
+			open := p.next()
+			index, err := p.parseRow(func(t texToken) bool { return t.isChar("]") })
+			if err != nil {
+				return texAtom{}, err
+			}
+			if !p.next().isChar("]") {
+				return texAtom{}, p.errorf(open.offset, "missing ]")
+			}
+			radicand, err := p.parseArgument(token)
+			if err != nil {
+				return texAtom{}, err
+			}
+			return texAtom{mathml: fmt.Sprintf("<mroot>%s%s</mroot>", radicand, texGroup(index))}, nil
+		}
+		radicand, err := p.parseArgument(token)
+		if err != nil {
+			return texAtom{}, err
+		}
+		return texAtom{mathml: fmt.Sprintf("<msqrt>%s</msqrt>", radicand)}, nil
+	case "overset", "underset", "stackrel":
+		script, err := p.parseArgument(token)
+		if err != nil {
+			return texAtom{}, err
+		}
+		base, err := p.parseArgument(token)
+		if err != nil {
+			return texAtom{}, err
+		}
+		if name == "underset" {
+			return texAtom{mathml: fmt.Sprintf("<munder>%s%s</munder>", base, script)}, nil
+		}
+		return texAtom{mathml: fmt.Sprintf("<mover>%s%s</mover>", base, script)}, nil
+	case "text", "textrm", "textnormal", "mbox", "textit", "textbf":
+		text, err := p.readRawGroup(token)
+		if err != nil {
+			return texAtom{}, err
+		}
+		text = strings.NewReplacer("\\{", "{", "\\}", "}", "\\$", "$", "\\%", "%", "\\&", "&", "\\_", "_", "\\#", "#", " ", " ").Replace(text)
+		switch name {
+		case "textit":
+			return texAtom{mathml: `<mtext style="font-style: italic">` + html.EscapeString(text) + "</mtext>"}, nil
+		case "textbf":
+			return texAtom{mathml: `<mtext style="font-weight: bold">` + html.EscapeString(text) + "</mtext>"}, nil
+		}
+		return texAtom{mathml: "<mtext>" + html.EscapeString(text) + "</mtext>"}, nil
+	case "operatorname":
+		text, err := p.readRawGroup(token)
+		if err != nil {
+			return texAtom{}, err
+		}
+		return texAtom{mathml: "<mi>" + html.EscapeString(strings.TrimSpace(text)) + "</mi>", function: true}, nil
+	case "left":
+		open, err := p.parseDelimiter(token)
+		if err != nil {
+			return texAtom{}, err
+		}
+		inner, err := p.parseRow(func(t texToken) bool { return t.isCommand("right") })
+		if err != nil {
+			return texAtom{}, err
+		}
+		right := p.next()
+		if !right.isCommand("right") {
+			return texAtom{}, p.errorf(token.offset, "missing \\right")
+		}
+		closing, err := p.parseDelimiter(right)
+		if err != nil {
+			return texAtom{}, err
+		}
+		return texAtom{mathml: "<mrow>" + texFence(open) + inner + texFence(closing) + "</mrow>"}, nil
+	case "right":
+		return texAtom{}, p.errorf(token.offset, "unexpected \\right")
+	case "not":
+		next := p.next()
+		var text string
+		if next.kind == texTokenCommand {
+			symbol, ok := texSymbols[next.value]
+			if !ok {
+				return texAtom{}, p.errorf(next.offset, "unknown command \\%s", next.value)
+			}
+			text = symbol.text
+		} else if next.kind == texTokenChar {
+			text = next.value
+		} else {
+			return texAtom{}, p.errorf(token.offset, "missing argument for \\not")
+		}
+		if negated, ok := texNegations[text]; ok {
+			return texAtom{mathml: "<mo>" + negated + "</mo>"}, nil
+		}
+		return texAtom{mathml: "<mo>" + html.EscapeString(text) + "̸</mo>"}, nil
+	case "pmod":
+		argument, err := p.parseArgument(token)
+		if err != nil {
+			return texAtom{}, err
+		}
+		return texAtom{mathml: `<mrow><mspace width="0.4444em"/><mo>(</mo><mi>mod</mi><mspace width="0.3333em"/>` + argument + "<mo>)</mo></mrow>"}, nil
+	case "bmod":
+		return texAtom{mathml: `<mo lspace="0.2222em" rspace="0.2222em">mod</mo>`}, nil
+	case "mod":
+		return texAtom{mathml: `<mo lspace="0.8889em" rspace="0.3333em">mod</mo>`}, nil
+	case "begin":
+		return p.parseEnvironment(token)
+	case "end":
+		return texAtom{}, p.errorf(token.offset, "unexpected \\end")
+	case "\\":
+		return texAtom{}, p.errorf(token.offset, "unexpected \\\\")
+	case "":
+		return texAtom{}, p.errorf(token.offset, "missing command name after \\")
+	}
+	return texAtom{}, p.errorf(token.offset, "unknown command \\%s", name)
+}
+
+// parseDelimiter parses the delimiter following \left, \right, and \big.
+// "." is an empty delimiter.
+func (p *texParser) parseDelimiter(command texToken) (string, error) {
+	token := p.next()
+	switch token.kind {
+	case texTokenChar:
+		switch token.value {
+		case ".":
+			return "", nil
+		case "(", ")", "[", "]", "|", "/":
+			return token.value, nil
+		case "<":
+			return "⟨", nil
+		case ">":
+			return "⟩", nil
+		}
+	case texTokenCommand:
+		if delimiter, ok := texDelimiters[token.value]; ok {
+			return delimiter, nil
+		}
+	}
+	return "", p.errorf(command.offset, "missing delimiter for %s", command)
+}
+
+func texFence(delimiter string) string {
+	if delimiter == "" {
+		return ""
+	}
+	return `<mo fence="true" stretchy="true" symmetric="true">` + html.EscapeString(delimiter) + "</mo>"
+}
+
+// texEnvironment defines how the rows of an environment are rendered.
+type texEnvironment struct {
+	open        string
+	close       string
+	columnAlign func(column int) string
+	display     bool
+}
+
+func alternateColumnAlign(column int) string {
+	if column%2 == 0 {
+		return "right"
+	}
+	return "left"
+}
+
+var texEnvironments = map[string]texEnvironment{
+	"matrix":   {},
+	"pmatrix":  {open: "(", close: ")"},
+	"bmatrix":  {open: "[", close: "]"},
+	"Bmatrix":  {open: "{", close: "}"},
+	"vmatrix":  {open: "|", close: "|"},
+	"Vmatrix":  {open: "‖", close: "‖"},
+	"cases":    {open: "{", columnAlign: func(int) string { return "left" }},
+	"aligned":  {columnAlign: alternateColumnAlign, display: true},
+	"align":    {columnAlign: alternateColumnAlign, display: true},
+	"align*":   {columnAlign: alternateColumnAlign, display: true},
+	"split":    {columnAlign: alternateColumnAlign, display: true},
+	"gathered": {display: true},
+	"array":    {},
+}
+
+func (p *texParser) parseEnvironment(begin texToken) (texAtom, error) {
+	name, err := p.readRawGroup(begin)
+	if err != nil {
+		return texAtom{}, err
+	}
+	environment, ok := texEnvironments[name]
+	if !ok {
+		return texAtom{}, p.errorf(begin.offset, "unknown environment %s", name)
+	}
+	if name == "array" {
+		spec, err := p.readRawGroup(begin)
+		if err != nil {
+			return texAtom{}, err
+		}
+		var aligns []string
+		for _, c := range spec {
+			switch c {
+			case 'l':
+				aligns = append(aligns, "left")
+			case 'c':
+				aligns = append(aligns, "center")
+			case 'r':
+				aligns = append(aligns, "right")
+			}
+		}
+		environment.columnAlign = func(column int) string {
+			if column < len(aligns) {
+				return aligns[column]
+			}
+			return "center"
+		}
+	}
+	var rows [][]string
+	var cells []string
+	for {
+		cell, err := p.parseRow(func(t texToken) bool {
+			return t.isChar("&") || t.isCommand("\\") || t.isCommand("end")
+		})
+		if err != nil {
+			return texAtom{}, err
+		}
+		cells = append(cells, cell)
+		token := p.next()
+		switch {
+		case token.isChar("&"):
+			continue
+		case token.isCommand("\\"):
+			rows = append(rows, cells)
+			cells = nil
+			continue
+		case token.isCommand("end"):
+			endName, err := p.readRawGroup(token)
+			if err != nil {
+				return texAtom{}, err
+			}
+			if endName != name {
+				return texAtom{}, p.errorf(token.offset, "\\begin{%s} ended by \\end{%s}", name, endName)
+			}
+		default:
+			return texAtom{}, p.errorf(begin.offset, "missing \\end{%s}", name)
+		}
+		break
+	}
+	// a trailing "\\" doesn't add an empty row
+	if len(cells) > 1 || cells[0] != "" || len(rows) == 0 {
+		rows = append(rows, cells)
+	}
+	table := texTableCells(rows, environment.columnAlign)
+	if environment.display {
+		table = `<mstyle displaystyle="true" scriptlevel="0">` + table + "</mstyle>"
+	}
+	if environment.open == "" && environment.close == "" {
+		return texAtom{mathml: table}, nil
+	}
+	return texAtom{mathml: "<mrow>" + texFence(environment.open) + table + texFence(environment.close) + "</mrow>"}, nil
+}
+
+// texTable renders rows with a single column.
+func texTable(rows []string, columnAlign func(int) string) string {
+	cells := make([][]string, len(rows))
+	for i, row := range rows {
+		cells[i] = []string{row}
+	}
+	return texTableCells(cells, columnAlign)
+}
+
+func texTableCells(rows [][]string, columnAlign func(int) string) string {
+	var b strings.Builder
+	b.WriteString("<mtable>")
+	for _, row := range rows {
+		b.WriteString("<mtr>")
+		for column, cell := range row {
+			if columnAlign != nil {
+				fmt.Fprintf(&b, `<mtd columnalign="%s">%s</mtd>`, columnAlign(column), cell)
+			} else {
+				fmt.Fprintf(&b, "<mtd>%s</mtd>", cell)
+			}
+		}
+		b.WriteString("</mtr>")
+	}
+	b.WriteString("</mtable>")
+	return b.String()
+}
+
+// texRow wraps the elements in a row unless there is a single element.
+func texRow(elements []string) string {
+	if len(elements) == 1 {
+		return elements[0]
+	}
+	return "<mrow>" + strings.Join(elements, "") + "</mrow>"
+}
+
+// texGroup ensures that the content of a group is a single element.
+func texGroup(row string) string {
+	if row == "" {
+		return "<mrow></mrow>"
+	}
+	return row
+}
+
+func (p *texParser) applyFont(text string) string {
+	if p.font == nil || p.font.normal {
+		return text
+	}
+	var b strings.Builder
+	for _, r := range text {
+		b.WriteRune(p.font.apply(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,120 @@
+package build
+
+import (
+	"strings"
+	"testing"
+)
+
+// mathMLBody returns the MathML of the expression without the math element and the TeX annotation.
+func mathMLBody(mathml string) string {
+	start := strings.Index(mathml, "<semantics>") + len("<semantics>")
+	end := strings.Index(mathml, "<annotation")
+	return mathml[start:end]
+}
+
+func TestTexToMathML(t *testing.T) {
+	tests := []struct {
+		tex      string
+		expected string
+	}{
+		{`x`, `<mi>x</mi>`},
+		{`12.5`, `<mn>12.5</mn>`},
+		{`x+1`, `<mrow><mi>x</mi><mo>+</mo><mn>1</mn></mrow>`},
+		{`\alpha`, `<mi>α</mi>`},
+		{`\Gamma`, `<mi mathvariant="normal">Γ</mi>`},
+		{`x^2`, `<msup><mi>x</mi><mn>2</mn></msup>`},
+		{`x_i`, `<msub><mi>x</mi><mi>i</mi></msub>`},
+		{`x_i^2`, `<msubsup><mi>x</mi><mi>i</mi><mn>2</mn></msubsup>`},
+		{`x'`, `<msup><mi>x</mi><mo>′</mo></msup>`},
+		{`f''`, `<msup><mi>f</mi><mrow><mo>′</mo><mo>′</mo></mrow></msup>`},
+		{`\frac{a}{b}`, `<mfrac><mi>a</mi><mi>b</mi></mfrac>`},
+		{`\dfrac12`, `<mstyle displaystyle="true" scriptlevel="0"><mfrac><mn>1</mn><mn>2</mn></mfrac></mstyle>`},
+		{`\binom{n}{k}`, `<mrow><mo>(</mo><mfrac linethickness="0"><mi>n</mi><mi>k</mi></mfrac><mo>)</mo></mrow>`},
+		{`\sqrt{x}`, `<msqrt><mi>x</mi></msqrt>`},
+		{`\sqrt[3]{x}`, `<mroot><mi>x</mi><mn>3</mn></mroot>`},
+		{`\sum_{i=1}^n i`, `<mrow><munderover><mo>∑</mo><mrow><mi>i</mi><mo>=</mo><mn>1</mn></mrow><mi>n</mi></munderover><mi>i</mi></mrow>`},
+		{`\sum\limits_{i=1}^n`, `<munderover><mo>∑</mo><mrow><mi>i</mi><mo>=</mo><mn>1</mn></mrow><mi>n</mi></munderover>`},
+		{`\int_0^1`, `<msubsup><mo>∫</mo><mn>0</mn><mn>1</mn></msubsup>`},
+		{`\lim_{x\to 0}`, `<munder><mo movablelimits="true" form="prefix">lim</mo><mrow><mi>x</mi><mo>→</mo><mn>0</mn></mrow></munder>`},
+		{`\sin x`, "<mrow><mrow><mi>sin</mi><mo>\u2061</mo></mrow><mi>x</mi></mrow>"},
+		{`\operatorname{foo} x`, "<mrow><mrow><mi>foo</mi><mo>\u2061</mo></mrow><mi>x</mi></mrow>"},
+		{`\mathbb{R}`, `<mi>ℝ</mi>`},
+		{`\mathrm{d}x`, `<mrow><mi mathvariant="normal">d</mi><mi>x</mi></mrow>`},
+		{`\mathbf{v}`, `<mi>𝐯</mi>`},
+		{`\text{if } x`, `<mrow><mtext>if </mtext><mi>x</mi></mrow>`},
+		{`\hat{x}`, `<mover accent="true"><mi>x</mi><mo stretchy="false">^</mo></mover>`},
+		{`\vec{v}`, `<mover accent="true"><mi>v</mi><mo stretchy="false">→</mo></mover>`},
+		{`\overline{AB}`, `<mover accent="true"><mrow><mi>A</mi><mi>B</mi></mrow><mo stretchy="true">‾</mo></mover>`},
+		{`\left( x \right)`, `<mrow><mo fence="true" stretchy="true" symmetric="true">(</mo><mi>x</mi><mo fence="true" stretchy="true" symmetric="true">)</mo></mrow>`},
+		{`\left. x \right|`, `<mrow><mi>x</mi><mo fence="true" stretchy="true" symmetric="true">|</mo></mrow>`},
+		{`\bigl(`, `<mo minsize="1.2em" maxsize="1.2em">(</mo>`},
+		{`a \not= b`, `<mrow><mi>a</mi><mo>≠</mo><mi>b</mi></mrow>`},
+		{`a \leq b`, `<mrow><mi>a</mi><mo>≤</mo><mi>b</mi></mrow>`},
+		{`a \quad b`, `<mrow><mi>a</mi><mspace width="1em"/><mi>b</mi></mrow>`},
+		{`\overset{!}{=}`, `<mover><mo>=</mo><mo>!</mo></mover>`},
+		{`\underset{x}{\max}`, `<munder><mo movablelimits="true" form="prefix">max</mo><mi>x</mi></munder>`},
+		{`a \pmod{n}`, `<mrow><mi>a</mi><mrow><mspace width="0.4444em"/><mo>(</mo><mi>mod</mi><mspace width="0.3333em"/><mi>n</mi><mo>)</mo></mrow></mrow>`},
+		{`\begin{pmatrix} a & b \\ c & d \end{pmatrix}`, `<mrow><mo fence="true" stretchy="true" symmetric="true">(</mo><mtable><mtr><mtd><mi>a</mi></mtd><mtd><mi>b</mi></mtd></mtr><mtr><mtd><mi>c</mi></mtd><mtd><mi>d</mi></mtd></mtr></mtable><mo fence="true" stretchy="true" symmetric="true">)</mo></mrow>`},
+		{`\begin{cases} 1 & x > 0 \\ 0 & \text{otherwise} \end{cases}`, `<mrow><mo fence="true" stretchy="true" symmetric="true">{</mo><mtable><mtr><mtd columnalign="left"><mn>1</mn></mtd><mtd columnalign="left"><mrow><mi>x</mi><mo>&gt;</mo><mn>0</mn></mrow></mtd></mtr><mtr><mtd columnalign="left"><mn>0</mn></mtd><mtd columnalign="left"><mtext>otherwise</mtext></mtd></mtr></mtable></mrow>`},
+		{`\begin{aligned} a &= b \\ c &= d \end{aligned}`, `<mstyle displaystyle="true" scriptlevel="0"><mtable><mtr><mtd columnalign="right"><mi>a</mi></mtd><mtd columnalign="left"><mrow><mo>=</mo><mi>b</mi></mrow></mtd></mtr><mtr><mtd columnalign="right"><mi>c</mi></mtd><mtd columnalign="left"><mrow><mo>=</mo><mi>d</mi></mrow></mtd></mtr></mtable></mstyle>`},
+		{`a \\ b`, `<mtable><mtr><mtd><mi>a</mi></mtd></mtr><mtr><mtd><mi>b</mi></mtd></mtr></mtable>`},
+		{`\{ x \}`, `<mrow><mo>{</mo><mi>x</mi><mo>}</mo></mrow>`},
+		{`x < y`, `<mrow><mi>x</mi><mo>&lt;</mo><mi>y</mi></mrow>`},
+	}
+	for _, test := range tests {
+		mathml, err := texToMathML(test.tex, false)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.tex, err)
+			continue
+		}
+		if body := mathMLBody(mathml); body != test.expected {
+			t.Errorf("%q:\n got: %s\nwant: %s", test.tex, body, test.expected)
+		}
+	}
+}
+
+func TestTexToMathMLDisplay(t *testing.T) {
+	mathml, err := texToMathML("x < 1", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<math xmlns="http://www.w3.org/1998/Math/MathML" display="block"><semantics><mrow><mi>x</mi><mo>&lt;</mo><mn>1</mn></mrow><annotation encoding="application/x-tex">x &lt; 1</annotation></semantics></math>`
+	if mathml != expected {
+		t.Errorf("\n got: %s\nwant: %s", mathml, expected)
+	}
+}
+
+func TestTexToMathMLErrors(t *testing.T) {
+	tests := []struct {
+		tex     string
+		offset  int
+		message string
+	}{
+		{`a & b`, 2, `unexpected &`},
+		{`\frac{a}`, 0, `missing argument for \frac`},
+		{`x^2^3`, 3, `double superscript`},
+		{`\unknown`, 0, `unknown command \unknown`},
+		{`{x`, 0, `missing closing brace`},
+		{`x}`, 1, `unexpected closing brace`},
+		{`\begin{foo}\end{foo}`, 0, `unknown environment foo`},
+		{`\begin{matrix} a \end{pmatrix}`, 17, `\begin{matrix} ended by \end{pmatrix}`},
+		{`\left( x`, 0, `missing \right`},
+		{`x_`, 1, `missing argument for _`},
+		{"a +\n\\nope", 4, `unknown command \nope`},
+		{`\sqrt[3`, 5, `missing ]`},
+		{`\text{x`, 5, `missing closing brace`},
+		{`\mathbb`, 0, `missing argument for \mathbb`},
+		{`\left<`, 0, `missing \right`},
+	}
+	for _, test := range tests {
+		_, err := texToMathML(test.tex, false)
+		texErr, ok := err.(*TexError)
+		if !ok {
+			t.Errorf("%q: expected a TexError, got %v", test.tex, err)
+			continue
+		}
+		if texErr.Offset != test.offset || texErr.Message != test.message {
+			t.Errorf("%q: got %d %q, want %d %q", test.tex, texErr.Offset, texErr.Message, test.offset, test.message)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package build
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+var templateFuncs = template.FuncMap{
+	"navPages": func(pages []NavPage, currentNavPageHref string) navPagesData {
+		return navPagesData{pages, currentNavPageHref}
+	},
+	"join": strings.Join,
+}
+
+// parseDefaultTemplate parses the embedded layout, template.html,
+// and its partials: head.html, header.html, sidebar.html, and footer.html.
+func parseDefaultTemplate() (*template.Template, error) {
+	return template.New("template.html").Funcs(templateFuncs).ParseFS(embedded, "assets/*.html")
+}
+
+// LoadTheme replaces the embedded layout and partials with the HTML files of the same name inside dir.
+// Files that are not defined fall back to the embedded ones.
+func (builder *HTMLBuilder) LoadTheme(dir string) error {
+	themeFilenames, err := fs.Glob(os.DirFS(dir), "*.html")
+	if err != nil {
+		return err
+	}
+	if len(themeFilenames) == 0 {
+		return nil
+	}
+	themeTemplate, err := parseDefaultTemplate()
+	if err != nil {
+		return err
+	}
+	themeTemplate, err = themeTemplate.ParseFS(os.DirFS(dir), "*.html")
+	if err != nil {
+		return &ThemeError{dir, err}
+	}
+	// execute the template once so that references to unknown fields are reported now
+	// instead of when a page is generated
+	if err := themeTemplate.ExecuteTemplate(io.Discard, "template.html", sampleData()); err != nil {
+		return &ThemeError{dir, err}
+	}
+	builder.tmpl = themeTemplate
+	return nil
+}
+
+func sampleData() Data {
+	page := NavPage{Title: "Page", Href: "/page"}
+	return Data{
+		Markdown:           template.HTML("<h1>Page</h1>"),
+		Title:              "Page",
+		Toc:                []TocHeading{{Level: 2, Text: "Heading", Id: "heading"}},
+		PrevPage:           &page,
+		NextPage:           &page,
+		Description:        "Description",
+		Twitter:            "@twitter",
+		Url:                "https://example.com/page",
+		Canonical:          "https://example.com/page",
+		Keywords:           []string{"keyword"},
+		Draft:              true,
+		PublishDate:        "2024-01-01",
+		Name:               "Name",
+		NavSections:        []NavSection{{Title: "Section", Href: "/section", Pages: []NavPage{{Title: "Group", Pages: []NavPage{page}}, page}}},
+		CurrentNavPageHref: page.Href,
+		LogoImageSrc:       "/logo.svg",
+		OGImageURL:         "https://example.com/og-logo.png",
+		Stylesheets:        []string{"/main.css"},
+		Scripts:            []string{"/script.js"},
+		FaviconHref:        "/favicon.ico",
+		LiveReloadSrc:      "/reload",
+		SearchIndexSrc:     "/search-index.json",
+		SearchScriptSrc:    "/search.js",
+		CopyButton:         true,
+		MermaidSrc:         "/mermaid.min.js",
+		MermaidIntegrity:   "sha384-",
+	}
+}
+
+type ThemeError struct {
+	Dir string
+	Err error
+}
+
+func (e *ThemeError) Error() string {
+	return fmt.Sprintf("invalid theme in %s: %v", e.Dir, e.Err)
+}
+
+func (e *ThemeError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,41 @@
+package build
+
+import (
+	"github.com/yuin/goldmark/ast"
+)
+
+const defaultTocDepth = 4
+
+type TocHeading struct {
+	Level int
+	Text  string
+	Id    string
+}
+
+// collectTocHeadings returns the h2 to h[maxLevel] headings of the document in order.
+func collectTocHeadings(document ast.Node, source []byte, maxLevel int) []TocHeading {
+	var headings []TocHeading
+	ast.Walk(document, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		heading, ok := n.(*ast.Heading)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		if heading.Level < 2 || heading.Level > maxLevel {
+			return ast.WalkSkipChildren, nil
+		}
+		id, ok := heading.AttributeString("id")
+		if !ok {
+			return ast.WalkSkipChildren, nil
+		}
+		headings = append(headings, TocHeading{
+			Level: heading.Level,
+			Text:  string(heading.Text(source)),
+			Id:    string(id.([]byte)),
+		})
+		return ast.WalkSkipChildren, nil
+	})
+	return headings
+}
@@ -1,44 +1,26 @@
 package build
 
 import (
-	"crypto/sha1"
-	"embed"
-	"encoding/hex"
-	"encoding/json"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/pilcrowOnPaper/malta/build"
 	"github.com/pilcrowOnPaper/malta/utils"
-	"github.com/yuin/goldmark"
-	"github.com/yuin/goldmark/extension"
-	"github.com/yuin/goldmark/parser"
-	"github.com/yuin/goldmark/renderer"
-	"github.com/yuin/goldmark/util"
 )
 
-var config struct {
-	Name         string                 `json:"name"`
-	Description  string                 `json:"description"`
-	Domain       string                 `json:"domain"`
-	Twitter      string                 `json:"twitter"`
-	Sidebar      []SidebarSectionConfig `json:"sidebar"`
-	AssetHashing bool                   `json:"asset_hashing"`
-}
-
 var markdownFilePaths []string
 
-//go:embed assets/*
-var embedded embed.FS
-
 func BuildCommand() int {
-	configJson, err := os.ReadFile("malta.config.json")
+	config, err := build.ParseConfigFile()
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
+		var missingConfigFileError *build.MissingConfigFileError
+		if errors.As(err, &missingConfigFileError) {
 			fmt.Println("Missing 'malta.config.json'")
 			return 1
 		}
@@ -46,20 +28,6 @@ func BuildCommand() int {
 		return 1
 	}
 
-	json.Unmarshal(configJson, &config)
-	if config.Name == "" {
-		fmt.Println("Missing config: name")
-		return 1
-	}
-	if config.Domain == "" {
-		fmt.Println("Missing config: domain")
-		return 1
-	}
-	if config.Description == "" {
-		fmt.Println("Missing config: description")
-		return 1
-	}
-
 	dirEntries, err := os.ReadDir(".")
 	if err != nil {
 		fmt.Println(err)
@@ -99,55 +67,105 @@ func BuildCommand() int {
 		}
 	}
 
-	cssAssets := []Asset{}
+	assets := []Asset{}
 	assetFilenames, _ := build.GetAssetFilenames()
 	for _, assetFilename := range assetFilenames {
-		if filepath.Ext(assetFilename) != ".css" {
+		if filepath.Ext(assetFilename) != ".css" && filepath.Ext(assetFilename) != ".js" {
 			continue
 		}
 		asset := Asset{
 			Filename: assetFilename,
 		}
 		if config.AssetHashing {
-			file, err := embedded.Open(filepath.Join("assets", asset.Filename))
+			file, err := build.GetAsset(asset.Filename)
 			if err != nil {
 				fmt.Println(err)
 				return 1
 			}
 			defer file.Close()
-			css, _ := io.ReadAll(file)
-			asset.OutputFilename = getHashedFilename(css, asset.Filename)
+			data, _ := io.ReadAll(file)
+			asset.OutputFilename = utils.GetHashedFilename(data, asset.Filename)
 		} else {
 			asset.OutputFilename = asset.Filename
 		}
-		cssAssets = append(cssAssets, asset)
+		assets = append(assets, asset)
+	}
+
+	var highlightCSS bytes.Buffer
+	if err := build.GenerateHighlightCSS(config.Highlight, &highlightCSS); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	highlightAsset := ProjectAsset{OutputFilename: "highlight.css", Data: highlightCSS.Bytes()}
+	if config.AssetHashing {
+		highlightAsset.OutputFilename = utils.GetHashedFilename(highlightAsset.Data, highlightAsset.OutputFilename)
+	}
+
+	styleSheetProjectAssets, err := readProjectAssets(config.CSS, config.AssetHashing)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	scriptProjectAssets, err := readProjectAssets(config.Scripts, config.AssetHashing)
+	if err != nil {
+		fmt.Println(err)
+		return 1
 	}
 
 	if config.AssetHashing && logoFilename != "" {
-		logoFilename = getHashedFilename(logoFile, logoFilename)
+		logoFilename = utils.GetHashedFilename(logoFile, logoFilename)
 	}
 	if config.AssetHashing && ogLogoFilename != "" {
-		ogLogoFilename = getHashedFilename(ogLogoFile, ogLogoFilename)
+		ogLogoFilename = utils.GetHashedFilename(ogLogoFile, ogLogoFilename)
 	}
 
-	navSections := []build.NavSection{}
-	for _, sidebarSection := range config.Sidebar {
-		navSection := build.NavSection{Title: sidebarSection.Title, Pages: []build.NavPage{}}
-		for _, sidebarSectionPage := range sidebarSection.Pages {
-			navPage := build.NavPage{Title: sidebarSectionPage[0], Href: sidebarSectionPage[1]}
-			navSection.Pages = append(navSection.Pages, navPage)
-		}
-		navSections = append(navSections, navSection)
+	if err := filepath.Walk("pages", walkPagesDir); err != nil {
+		fmt.Println(err)
+		return 1
 	}
 
-	if err := filepath.Walk("pages", walkPagesDir); err != nil {
+	frontmatterErrors, err := build.ValidatePageMatter(markdownFilePaths)
+	if err != nil {
 		fmt.Println(err)
 		return 1
 	}
+	invalidAttributeCount := 0
+	for _, frontmatterError := range frontmatterErrors {
+		fmt.Println(frontmatterError)
+		if !frontmatterError.Warning {
+			invalidAttributeCount++
+		}
+	}
+	if invalidAttributeCount > 0 {
+		fmt.Printf("Found %d frontmatter errors\n", invalidAttributeCount)
+		return 1
+	}
 
-	markdown := goldmark.New(goldmark.WithExtensions(extension.Table))
-	markdown.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(&codeBlockLinksAstTransformer{}, 500)), parser.WithAutoHeadingID())
-	markdown.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&codeBlockLinksRenderer{}, 100)))
+	args := utils.ParseArgs(os.Args[2:])
+	if _, ok := args["drafts"]; !ok {
+		publishedFilePaths, draftURLPaths, err := build.ExcludeDraftPages(markdownFilePaths)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		markdownFilePaths = publishedFilePaths
+		config.NavSections = build.ExcludeDraftNavPages(config.NavSections, draftURLPaths)
+	}
+
+	if _, ok := args["strict"]; ok {
+		linkErrors, err := build.CheckLinks(markdownFilePaths, config.NavSections, config.Markdown)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		for _, linkError := range linkErrors {
+			fmt.Println(linkError)
+		}
+		if len(linkErrors) > 0 {
+			fmt.Printf("Found %d broken links\n", len(linkErrors))
+			return 1
+		}
+	}
 
 	os.RemoveAll("dist")
 
@@ -157,13 +175,36 @@ func BuildCommand() int {
 	}
 
 	styleSheetFilenames := []string{}
-	for _, asset := range cssAssets {
+	var searchScriptFilename string
+	for _, asset := range assets {
+		if filepath.Ext(asset.Filename) == ".css" {
+			styleSheetFilenames = append(styleSheetFilenames, asset.OutputFilename)
+		}
+		if asset.Filename == "search.js" {
+			searchScriptFilename = asset.OutputFilename
+		}
+	}
+	styleSheetFilenames = append(styleSheetFilenames, highlightAsset.OutputFilename)
+	for _, asset := range styleSheetProjectAssets {
 		styleSheetFilenames = append(styleSheetFilenames, asset.OutputFilename)
 	}
 
-	builder := build.NewBuilder(config.Name, config.Description, config.Domain, navSections, styleSheetFilenames)
-	if config.Twitter != "" {
-		builder.SetSiteTwitterHandle(config.Twitter)
+	var searchIndex bytes.Buffer
+	if err := build.GenerateSearchIndex(markdownFilePaths, config.Markdown, &searchIndex); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	searchIndexFilename := "search-index.json"
+	if config.AssetHashing {
+		searchIndexFilename = utils.GetHashedFilename(searchIndex.Bytes(), searchIndexFilename)
+	}
+
+	builder := build.NewBuilder(config.Name, config.Description, config.Domain, config.NavSections, styleSheetFilenames)
+	if config.TwitterHandle != "" {
+		builder.SetSiteTwitterHandle(config.TwitterHandle)
+	}
+	if len(config.Keywords) > 0 {
+		builder.SetSiteKeywords(config.Keywords)
 	}
 	if favicon {
 		builder.IncludeFavicon()
@@ -174,7 +215,27 @@ func BuildCommand() int {
 	if logoFilename != "" {
 		builder.SetLogoFile(logoFilename)
 	}
+	builder.EnableSearch(searchIndexFilename, searchScriptFilename)
+	if config.AssetHashing {
+		builder.EnableAssetHashing()
+	}
+	builder.SetMarkdownConfig(config.Markdown)
+	if config.CopyButton {
+		builder.EnableCopyButton()
+	}
+	for language, command := range config.Diagrams {
+		builder.SetDiagramRenderer(language, &build.CommandDiagramRenderer{Command: command})
+	}
+	builder.SetMermaidScript(config.Mermaid)
+	for _, asset := range scriptProjectAssets {
+		builder.AddScript(asset.OutputFilename)
+	}
+	if err := builder.LoadTheme("theme"); err != nil {
+		fmt.Println(err)
+		return 1
+	}
 
+	pageErrorCount := 0
 	for _, markdownFilePath := range markdownFilePaths {
 		markdownFile, _ := os.Open(markdownFilePath)
 		defer markdownFile.Close()
@@ -194,18 +255,17 @@ func BuildCommand() int {
 
 		defer dstHtmlFile.Close()
 
-		urlPathname := strings.Replace(strings.Replace(dstPath, "dist", "", 1), ".html", "", 1)
-		urlPathname = strings.Replace(urlPathname, "/index", "", 1)
-		if urlPathname == "" {
-			urlPathname = "/"
-		}
-
-		err = builder.GenerateHTML(urlPathname, markdownFile, dstHtmlFile)
+		err = builder.GenerateHTML(build.GetPageURLPath(markdownFilePath), markdownFilePath, markdownFile, dstHtmlFile)
 		if err != nil {
+			// keep building the other pages to report every error at once
 			fmt.Println(err)
-			return 1
+			pageErrorCount++
 		}
 	}
+	if pageErrorCount > 0 {
+		fmt.Printf("Failed to build %d pages\n", pageErrorCount)
+		return 1
+	}
 
 	notFoundDstHtmlFile, err := os.Create("dist/404.html")
 	if err != nil {
@@ -218,8 +278,8 @@ func BuildCommand() int {
 		return 1
 	}
 
-	for _, asset := range cssAssets {
-		src, err := embedded.Open(filepath.Join("assets", asset.Filename))
+	for _, asset := range assets {
+		src, err := build.GetAsset(asset.Filename)
 		if err != nil {
 			fmt.Println(err)
 			return 1
@@ -234,6 +294,26 @@ func BuildCommand() int {
 		io.Copy(dst, src)
 	}
 
+	if err := copyPageFiles(builder.PageFiles()); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	projectAssets := append([]ProjectAsset{highlightAsset}, styleSheetProjectAssets...)
+	for _, asset := range append(projectAssets, scriptProjectAssets...) {
+		dstPath := filepath.Join("dist", filepath.FromSlash(asset.OutputFilename))
+		if err := os.MkdirAll(filepath.Dir(dstPath), os.ModePerm); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		if err := os.WriteFile(dstPath, asset.Data, os.ModePerm); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+	}
+
+	os.WriteFile(filepath.Join("dist", searchIndexFilename), searchIndex.Bytes(), os.ModePerm)
+
 	if logoFilename != "" {
 		os.WriteFile(filepath.Join("dist", logoFilename), logoFile, os.ModePerm)
 	}
@@ -241,6 +321,37 @@ func BuildCommand() int {
 		os.WriteFile(filepath.Join("dist", ogLogoFilename), ogLogoFile, os.ModePerm)
 	}
 
+	sitemapFile, err := os.Create("dist/sitemap.xml")
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer sitemapFile.Close()
+	if err := build.GenerateSitemap(config.Domain, markdownFilePaths, config.SitemapLastMod, sitemapFile); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	if _, err := os.Stat(filepath.Join("public", "robots.txt")); err == nil {
+		// copied from the public directory
+	} else if robotsTxt, err := os.ReadFile("robots.txt"); err == nil {
+		os.WriteFile("dist/robots.txt", robotsTxt, os.ModePerm)
+	} else if errors.Is(err, os.ErrNotExist) {
+		robotsTxtFile, err := os.Create("dist/robots.txt")
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		defer robotsTxtFile.Close()
+		if err := build.GenerateRobotsTxt(config.Domain, robotsTxtFile); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+	} else {
+		fmt.Println(err)
+		return 1
+	}
+
 	if favicon {
 		faviconICO, err := os.ReadFile("favicon.ico")
 		if err != nil {
@@ -249,29 +360,104 @@ func BuildCommand() int {
 		}
 		os.WriteFile("dist/favicon.ico", faviconICO, os.ModePerm)
 	}
+
+	if err := copyPublicDir(); err != nil {
+		fmt.Println(err)
+		return 1
+	}
 	return 0
 }
 
+// copyPageFiles copies the files inside the pages directory referenced by the pages to dist.
+// Files that would overwrite a generated file are reported as an error.
+func copyPageFiles(pageFiles map[string]string) error {
+	for pageFilePath, outputFilename := range pageFiles {
+		dstPath := filepath.Join("dist", filepath.FromSlash(outputFilename))
+		if _, err := os.Stat(dstPath); err == nil {
+			return fmt.Errorf("%s conflicts with generated file %s", filepath.ToSlash(pageFilePath), filepath.ToSlash(dstPath))
+		}
+		if err := os.MkdirAll(filepath.Dir(dstPath), os.ModePerm); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(pageFilePath)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(dstPath, data, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyPublicDir mirrors the public directory into dist.
+// Files that would overwrite a generated file are reported as an error.
+func copyPublicDir() error {
+	err := filepath.Walk("public", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel("public", path)
+		if err != nil {
+			return err
+		}
+		dstPath := filepath.Join("dist", relPath)
+		if _, err := os.Stat(dstPath); err == nil {
+			return fmt.Errorf("%s conflicts with generated file %s", filepath.ToSlash(path), filepath.ToSlash(dstPath))
+		}
+		if err := os.MkdirAll(filepath.Dir(dstPath), os.ModePerm); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(dstPath, data, os.ModePerm)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
 func walkPagesDir(path string, info os.FileInfo, err error) error {
 	if err != nil {
 		return err
 	}
-	if info.IsDir() {
+	if info.IsDir() || filepath.Ext(path) != ".md" {
 		return nil
 	}
 	markdownFilePaths = append(markdownFilePaths, path)
 	return nil
 }
 
-func getHashedFilename(data []byte, filename string) string {
-	fileHash := sha1.Sum(data)
-	hashString := hex.EncodeToString(fileHash[:])
-	return hashString + filepath.Ext(filename)
+// readProjectAssets reads the CSS and JavaScript files defined in the config.
+// The output filename is the path relative to the project, or the file hash if assetHashing is enabled.
+func readProjectAssets(filenames []string, assetHashing bool) ([]ProjectAsset, error) {
+	projectAssets := []ProjectAsset{}
+	for _, filename := range filenames {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		asset := ProjectAsset{
+			OutputFilename: filepath.ToSlash(filepath.Clean(filename)),
+			Data:           data,
+		}
+		if assetHashing {
+			asset.OutputFilename = utils.GetHashedFilename(data, filename)
+		}
+		projectAssets = append(projectAssets, asset)
+	}
+	return projectAssets, nil
 }
 
-type SidebarSectionConfig struct {
-	Title string     `json:"title"`
-	Pages [][]string `json:"pages"`
+type ProjectAsset struct {
+	OutputFilename string
+	Data           []byte
 }
 
 type Asset struct {
@@ -0,0 +1,42 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pilcrowOnPaper/malta/build"
+)
+
+func CheckCommand() int {
+	config, err := build.ParseConfigFile()
+	if err != nil {
+		var missingConfigFileError *build.MissingConfigFileError
+		if errors.As(err, &missingConfigFileError) {
+			fmt.Println("Missing 'malta.config.json'")
+			return 1
+		}
+		fmt.Println(err)
+		return 1
+	}
+
+	markdownFilePaths, err := build.GetMarkdownFilePaths()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	linkErrors, err := build.CheckLinks(markdownFilePaths, config.NavSections, config.Markdown)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	for _, linkError := range linkErrors {
+		fmt.Println(linkError)
+	}
+	if len(linkErrors) > 0 {
+		fmt.Printf("Found %d broken links\n", len(linkErrors))
+		return 1
+	}
+	fmt.Println("No broken links found")
+	return 0
+}
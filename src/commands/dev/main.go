@@ -34,6 +34,10 @@ func DevCommand() int {
 		port = parsedPort
 	}
 
+	reloadBroadcaster := newReloadBroadcaster()
+	go watchProjectFiles(reloadBroadcaster.broadcast)
+	http.Handle(liveReloadEndpoint, reloadBroadcaster)
+
 	http.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
 		config, err := build.ParseConfigFile()
 		if err != nil {
@@ -51,11 +55,41 @@ func DevCommand() int {
 				cssAssetFilenames = append(cssAssetFilenames, assetFilename)
 			}
 		}
+		cssAssetFilenames = append(cssAssetFilenames, "highlight.css")
+		projectAssetFilenames := make(map[string]bool)
+		for _, filename := range config.CSS {
+			filename = filepath.ToSlash(filepath.Clean(filename))
+			cssAssetFilenames = append(cssAssetFilenames, filename)
+			projectAssetFilenames[filename] = true
+		}
 
 		builder := build.NewBuilder(config.Name, config.Description, fmt.Sprintf("http://localhost:%d", port), config.NavSections, cssAssetFilenames)
 		if config.TwitterHandle != "" {
 			builder.SetSiteTwitterHandle(config.TwitterHandle)
 		}
+		if len(config.Keywords) > 0 {
+			builder.SetSiteKeywords(config.Keywords)
+		}
+		builder.EnableLiveReload(liveReloadEndpoint)
+		builder.SetMarkdownConfig(config.Markdown)
+		if config.CopyButton {
+			builder.EnableCopyButton()
+		}
+		for language, command := range config.Diagrams {
+			builder.SetDiagramRenderer(language, &build.CommandDiagramRenderer{Command: command})
+		}
+		builder.SetMermaidScript(config.Mermaid)
+		builder.EnableSearch("search-index.json", "search.js")
+		for _, filename := range config.Scripts {
+			filename = filepath.ToSlash(filepath.Clean(filename))
+			builder.AddScript(filename)
+			projectAssetFilenames[filename] = true
+		}
+		if err := builder.LoadTheme("theme"); err != nil {
+			w.WriteHeader(500)
+			w.Write([]byte(fmt.Sprintf("Failed to load theme: %v", err)))
+			return
+		}
 
 		ogFilename, err := build.GetOGImageFilename()
 		if err == nil {
@@ -106,8 +140,77 @@ func DevCommand() int {
 			return
 		}
 
+		if req.URL.Path == "/search-index.json" {
+			markdownFilePaths, err := build.GetMarkdownFilePaths()
+			if err != nil {
+				w.WriteHeader(500)
+				w.Write([]byte(fmt.Sprintf("Failed to read pages: %v", err)))
+				return
+			}
+			var searchIndex bytes.Buffer
+			if err := build.GenerateSearchIndex(markdownFilePaths, config.Markdown, &searchIndex); err != nil {
+				w.WriteHeader(500)
+				w.Write([]byte(fmt.Sprintf("Failed to generate search index: %v", err)))
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			io.Copy(w, &searchIndex)
+			return
+		}
+
+		if req.URL.Path == "/highlight.css" {
+			w.Header().Set("Content-Type", "text/css; charset=utf-8")
+			if err := build.GenerateHighlightCSS(config.Highlight, w); err != nil {
+				w.WriteHeader(500)
+				w.Write([]byte(fmt.Sprintf("Failed to generate highlight.css: %v", err)))
+			}
+			return
+		}
+
+		if projectAssetFilenames[strings.TrimPrefix(req.URL.Path, "/")] {
+			data, err := os.ReadFile(filepath.FromSlash(strings.TrimPrefix(req.URL.Path, "/")))
+			if err != nil {
+				w.WriteHeader(500)
+				w.Write([]byte(fmt.Sprintf("Failed to read %s: %v", req.URL.Path, err)))
+				return
+			}
+			w.Header().Set("Content-Type", mime.TypeByExtension(filepath.Ext(req.URL.Path)))
+			w.Write(data)
+			return
+		}
+
+		if filepath.Ext(req.URL.Path) != ".md" {
+			if pageFile, err := openStaticFile("pages", req.URL.Path); err == nil {
+				defer pageFile.Close()
+				info, err := pageFile.Stat()
+				if err != nil {
+					w.WriteHeader(500)
+					w.Write([]byte(fmt.Sprintf("Failed to read %s: %v", pageFile.Name(), err)))
+					return
+				}
+				http.ServeContent(w, req, pageFile.Name(), info.ModTime(), pageFile)
+				return
+			}
+		}
+
+		if publicFile, err := openStaticFile("public", req.URL.Path); err == nil {
+			defer publicFile.Close()
+			info, err := publicFile.Stat()
+			if err != nil {
+				w.WriteHeader(500)
+				w.Write([]byte(fmt.Sprintf("Failed to read %s: %v", publicFile.Name(), err)))
+				return
+			}
+			http.ServeContent(w, req, publicFile.Name(), info.ModTime(), publicFile)
+			return
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			w.WriteHeader(500)
+			w.Write([]byte(fmt.Sprintf("Failed to read %s: %v", path.Join("public", req.URL.Path), err)))
+			return
+		}
+
 		fileExtension := filepath.Ext(req.URL.Path)
-		if fileExtension == ".css" {
+		if fileExtension == ".css" || fileExtension == ".js" {
 			if strings.Count(req.URL.Path, "/") != 1 {
 				w.Header().Set("Content-Type", "text/html; charset=utf-8")
 				w.WriteHeader(404)
@@ -126,7 +229,7 @@ func DevCommand() int {
 				w.Write([]byte(fmt.Sprintf("Failed to read %s: %v", filepath.Base(req.URL.Path), err)))
 				return
 			}
-			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Content-Type", mime.TypeByExtension(fileExtension))
 			io.Copy(w, asset)
 			return
 		}
@@ -145,7 +248,7 @@ func DevCommand() int {
 			}
 
 			var html bytes.Buffer
-			err = builder.GenerateHTML(req.URL.Path, file, &html)
+			err = builder.GenerateHTML(req.URL.Path, file.Name(), file, &html)
 			if err != nil {
 				w.WriteHeader(500)
 				w.Write([]byte(fmt.Sprintf("Failed to build %s: %v", file.Name(), err)))
@@ -179,6 +282,25 @@ func ResolveMarkdownFileFromHTTPRequestPath(reqPath string) (*os.File, error) {
 
 }
 
+// openStaticFile opens the file inside dir that matches the request path.
+// Directories are treated as missing files.
+func openStaticFile(dir string, reqPath string) (*os.File, error) {
+	file, err := os.Open(filepath.Join(dir, filepath.FromSlash(path.Clean("/"+reqPath))))
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, fs.ErrNotExist
+	}
+	return file, nil
+}
+
 func parseArgs(argList []string) map[string]string {
 	args := make(map[string]string)
 	for i := 0; i < len(argList); i++ {
@@ -0,0 +1,165 @@
+package dev
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pilcrowOnPaper/malta/build"
+	"github.com/pilcrowOnPaper/malta/utils"
+)
+
+const liveReloadEndpoint = "/__malta/reload"
+
+var watchedDirs = []string{"pages", "theme", "public"}
+
+// how often watched files are checked for changes
+const watchPollInterval = 200 * time.Millisecond
+
+type reloadBroadcaster struct {
+	mu      sync.Mutex
+	clients map[chan struct{}]struct{}
+}
+
+func newReloadBroadcaster() *reloadBroadcaster {
+	return &reloadBroadcaster{
+		clients: make(map[chan struct{}]struct{}),
+	}
+}
+
+func (b *reloadBroadcaster) subscribe() chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	client := make(chan struct{}, 1)
+	b.clients[client] = struct{}{}
+	return client
+}
+
+func (b *reloadBroadcaster) unsubscribe(client chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.clients, client)
+}
+
+func (b *reloadBroadcaster) broadcast() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for client := range b.clients {
+		select {
+		case client <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// ServeHTTP keeps the connection open as a server-sent event stream
+// and sends a "reload" event whenever a watched file changes.
+func (b *reloadBroadcaster) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(500)
+		w.Write([]byte("Streaming not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	client := b.subscribe()
+	defer b.unsubscribe(client)
+
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+	for {
+		select {
+		case <-client:
+			fmt.Fprint(w, "event: reload\ndata: \n\n")
+			flusher.Flush()
+		case <-req.Context().Done():
+			return
+		}
+	}
+}
+
+type watchedFile struct {
+	modTime time.Time
+	size    int64
+}
+
+// watchProjectFiles polls the project files and calls onChange once the files
+// have stopped changing, so that a single save only triggers a single reload.
+func watchProjectFiles(onChange func()) {
+	previous := snapshotProjectFiles()
+	var pending bool
+	for {
+		time.Sleep(watchPollInterval)
+		current := snapshotProjectFiles()
+		if !equalSnapshots(previous, current) {
+			previous = current
+			pending = true
+			continue
+		}
+		if pending {
+			pending = false
+			onChange()
+		}
+	}
+}
+
+// snapshotProjectFiles lists the config, the root files used by the site, the watched directories,
+// and the CSS and JavaScript files listed in the config.
+func snapshotProjectFiles() map[string]watchedFile {
+	snapshot := make(map[string]watchedFile)
+	dirEntries, _ := os.ReadDir(".")
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() {
+			continue
+		}
+		filename := dirEntry.Name()
+		filenameWithoutExtension := utils.FilenameWithoutExtension(filename)
+		if filename != "malta.config.json" && filename != "favicon.ico" && filenameWithoutExtension != "logo" && filenameWithoutExtension != "og-logo" {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		snapshot[filename] = watchedFile{info.ModTime(), info.Size()}
+	}
+	for _, dir := range watchedDirs {
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			snapshot[path] = watchedFile{info.ModTime(), info.Size()}
+			return nil
+		})
+	}
+	// the config is read on every poll so that added assets are watched without restarting
+	if config, err := build.ParseConfigFile(); err == nil {
+		for _, filename := range append(config.CSS, config.Scripts...) {
+			path := filepath.Clean(filename)
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			snapshot[path] = watchedFile{info.ModTime(), info.Size()}
+		}
+	}
+	return snapshot
+}
+
+func equalSnapshots(a map[string]watchedFile, b map[string]watchedFile) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, file := range a {
+		if b[path] != file {
+			return false
+		}
+	}
+	return true
+}
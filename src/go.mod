@@ -5,6 +5,7 @@ go 1.21.3
 require (
 	github.com/adrg/frontmatter v0.2.0
 	github.com/yuin/goldmark v1.6.0
+	gopkg.in/yaml.v2 v2.3.0
 )
 
 require github.com/alecthomas/chroma v0.10.0
@@ -12,5 +13,4 @@ require github.com/alecthomas/chroma v0.10.0
 require (
 	github.com/BurntSushi/toml v0.3.1 // indirect
 	github.com/dlclark/regexp2 v1.10.0 // indirect
-	gopkg.in/yaml.v2 v2.3.0 // indirect
 )
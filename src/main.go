@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/pilcrowOnPaper/malta/commands/build"
+	"github.com/pilcrowOnPaper/malta/commands/check"
 	"github.com/pilcrowOnPaper/malta/commands/dev"
 	"github.com/pilcrowOnPaper/malta/commands/preview"
 )
@@ -17,6 +18,7 @@ Usage:
 malta build   - build and generate HTML files
 malta preview - preview build
 malta dev     - start dev server
+malta check   - check for broken links
 
 `)
 		os.Exit(0)
@@ -30,6 +32,9 @@ malta dev     - start dev server
 	if os.Args[1] == "dev" {
 		os.Exit(dev.DevCommand())
 	}
+	if os.Args[1] == "check" {
+		os.Exit(check.CheckCommand())
+	}
 	fmt.Printf("Unknown command: %s\n", os.Args[1])
 	os.Exit(1)
 }
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"path/filepath"
 	"strings"
 )
@@ -37,3 +39,9 @@ func ParseArgs(argList []string) map[string]string {
 func FilenameWithoutExtension(filename string) string {
 	return filename[:len(filename)-len(filepath.Ext(filename))]
 }
+
+func GetHashedFilename(data []byte, filename string) string {
+	fileHash := sha1.Sum(data)
+	hashString := hex.EncodeToString(fileHash[:])
+	return hashString + filepath.Ext(filename)
+}